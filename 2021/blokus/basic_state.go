@@ -66,7 +66,10 @@ func (b *BasicState) Reset() {
 		b.notPlayedPieces[c] = make([]Piece, NumPieces)
 		copy(b.notPlayedPieces[c], AllPieces[:])
 		b.lastMoveMono[c] = false
+		b.isColorInvalid[c] = false
 	}
+	b.isPlayerTwoFirst = false
+	b.currentColor = ColorBlue
 }
 
 func (b *BasicState) Set(x, y uint8, c Color, hasPiece bool) {
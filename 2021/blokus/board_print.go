@@ -0,0 +1,115 @@
+package blokus
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// BoardFormat controls how PrintBoard renders a State
+type BoardFormat struct {
+	// ANSI enables colored output using ANSI escape sequences. Otherwise the colors are shown as the
+	// letters B, Y, R and G.
+	ANSI bool
+	// LastMove is highlighted, if it is not empty
+	LastMove Move
+	// ShowAnchors marks the free cells where AnchorColor could place its next piece, see IsAnchor.
+	ShowAnchors bool
+	AnchorColor Color
+}
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiDim   = "\x1b[2m"
+)
+
+var ansiBackground = [4]string{
+	ColorBlue:   "\x1b[44m",
+	ColorYellow: "\x1b[43m",
+	ColorRed:    "\x1b[41m",
+	ColorGreen:  "\x1b[42m",
+}
+
+var ansiForeground = [4]string{
+	ColorBlue:   "\x1b[34m",
+	ColorYellow: "\x1b[33m",
+	ColorRed:    "\x1b[31m",
+	ColorGreen:  "\x1b[32m",
+}
+
+// FormatBoard returns the board of s rendered by PrintBoard
+func FormatBoard(s State, f BoardFormat) string {
+	var sb strings.Builder
+	err := PrintBoard(&sb, s, f)
+	if err != nil {
+		panic(err)
+	}
+	return sb.String()
+}
+
+// PrintBoard renders the board of s as a 20x20 grid with x coordinates on top (tens above ones) and y coordinates
+// on the left.
+// Every cell is two characters wide. In plain text, filled cells show the color letter, cells of the last move
+// are marked with '*', anchors with '+', and empty cells with '.'.
+func PrintBoard(w io.Writer, s State, f BoardFormat) (err error) {
+	var lastMoveCells [20][20]bool
+	if !f.LastMove.IsEmpty() {
+		for _, pos := range f.LastMove.Transformation.Positions() {
+			x, y := f.LastMove.X+pos.X, f.LastMove.Y+pos.Y
+			if x < 20 && y < 20 {
+				lastMoveCells[x][y] = true
+			}
+		}
+	}
+
+	// x coordinates are printed vertically, tens above ones
+	var tens, ones strings.Builder
+	for x := 0; x < 20; x++ {
+		if x < 10 {
+			tens.WriteString("  ")
+		} else {
+			fmt.Fprintf(&tens, "%d ", x/10)
+		}
+		fmt.Fprintf(&ones, "%d ", x%10)
+	}
+	if _, err = fmt.Fprintf(w, "   %s\n   %s\n", tens.String(), ones.String()); err != nil {
+		return
+	}
+	for y := uint8(0); y < 20; y++ {
+		if _, err = fmt.Fprintf(w, "%2d ", y); err != nil {
+			return
+		}
+		for x := uint8(0); x < 20; x++ {
+			if _, err = io.WriteString(w, formatCell(s, f, x, y, lastMoveCells[x][y])); err != nil {
+				return
+			}
+		}
+		if _, err = fmt.Fprintln(w); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func formatCell(s State, f BoardFormat, x, y uint8, isLastMove bool) string {
+	c, hasPiece := s.At(x, y)
+	switch {
+	case hasPiece && f.ANSI && isLastMove:
+		return ansiBackground[c] + "[]" + ansiReset
+	case hasPiece && f.ANSI:
+		return ansiBackground[c] + "  " + ansiReset
+	case hasPiece && isLastMove:
+		return string([]byte{c.Letter(), '*'})
+	case hasPiece:
+		return string([]byte{c.Letter(), ' '})
+	case f.ShowAnchors && IsAnchor(s, f.AnchorColor, x, y):
+		if f.ANSI {
+			return ansiForeground[f.AnchorColor] + "+ " + ansiReset
+		}
+		return "+ "
+	case f.ANSI:
+		return ansiDim + ". " + ansiReset
+	default:
+		return ". "
+	}
+}
@@ -0,0 +1,36 @@
+package blokus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatBoard(t *testing.T) {
+	var s BasicState
+	s.SetStartPiece(PieceDomino)
+	MustApplyMove(&s, ColorBlue, NewMove(NewTransformedPiece(PieceDomino, RotationNone, false), 0, 0))
+	lastMove := NewMove(NewTransformedPiece(PieceDomino, RotationRight, false), 19, 0)
+	MustApplyMove(&s, ColorYellow, lastMove)
+
+	o := FormatBoard(&s, BoardFormat{
+		LastMove:    lastMove,
+		ShowAnchors: true,
+		AnchorColor: ColorBlue,
+	})
+	lines := strings.Split(o, "\n")
+	expected := []string{
+		"                       1 1 1 1 1 1 1 1 1 1 ",
+		"   0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 ",
+		" 0 B B . . . . . . . . . . . . . . . . . Y*",
+		" 1 . . + . . . . . . . . . . . . . . . . Y*",
+		" 2 . . . . . . . . . . . . . . . . . . . . ",
+	}
+	for i, e := range expected {
+		if lines[i] != e {
+			t.Errorf("line %d: expected\n%q\nbut got\n%q", i, e, lines[i])
+		}
+	}
+	if len(lines) != 23 {
+		t.Errorf("expected 23 lines but got %d", len(lines))
+	}
+}
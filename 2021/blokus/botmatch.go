@@ -1,10 +1,13 @@
 package blokus
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"sort"
+	"time"
 )
 
 func sortedPlayerNames(players map[string]Player) (playerNames []string) {
@@ -17,16 +20,26 @@ func sortedPlayerNames(players map[string]Player) (playerNames []string) {
 }
 
 type botMatchCommandLine struct {
-	RepeatGames uint
-	Player1     Player
-	Player2     Player
-	Player1Name string
-	Player2Name string
+	RepeatGames     uint
+	MoveTimeout     time.Duration
+	HardMoveTimeout time.Duration
+	Player1         Player
+	Player2         Player
+	Player1Name     string
+	Player2Name     string
+	JSONSummaryTo   string
+	CSVLogTo        string
+	JSONLLogTo      string
 }
 
 func (c *botMatchCommandLine) Parse(players map[string]Player) {
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	fs.UintVar(&c.RepeatGames, "n", 2, "repeat games between players (with alternating player order)")
+	fs.DurationVar(&c.MoveTimeout, "timeout", DefaultMoveTimeout, "time a player has for a move")
+	fs.DurationVar(&c.HardMoveTimeout, "hard-timeout", DefaultHardMoveTimeout, "time after which a player that did not answer is abandoned")
+	fs.StringVar(&c.JSONSummaryTo, "json", "", "write the summary of all games as JSON to this file")
+	fs.StringVar(&c.CSVLogTo, "csv", "", "write one CSV line per game to this file")
+	fs.StringVar(&c.JSONLLogTo, "jsonl", "", "write one JSON line per game to this file")
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s <player 1> <player 2> [flags]\n\nAvailable players:\n", os.Args[0])
@@ -61,11 +74,82 @@ func (c *botMatchCommandLine) Parse(players map[string]Player) {
 // You can invoke the executable like this: <name of executable> <name of first player> <name of second player>
 // By default, two games will be played. You can change this by adding the desired number of games with -n <number>
 // The first player position will alternate between the two players.
-// Results will be printed to stdout.
+// The move timeouts can be changed with -timeout <duration> and -hard-timeout <duration>.
+// Results will be printed to stdout. For further processing, a summary can be written as JSON with -json <file>,
+// and a line per game with -csv <file> or -jsonl <file>.
 func BotMatchMain(players map[string]Player) {
 	var cl botMatchCommandLine
 	cl.Parse(players)
-	result := RunRepeatedGames(cl.Player1, cl.Player2, cl.Player1Name, cl.Player2Name, cl.RepeatGames, os.Stdout)
+	var gameLogs []GameLog
+	for _, l := range []struct {
+		path   string
+		newLog func(w io.Writer) GameLog
+	}{{cl.CSVLogTo, NewCSVGameLog}, {cl.JSONLLogTo, NewJSONLGameLog}} {
+		if l.path == "" {
+			continue
+		}
+		f, err := os.Create(l.path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot create game log: %s\n", err)
+			os.Exit(2)
+		}
+		defer f.Close()
+		gameLogs = append(gameLogs, l.newLog(f))
+	}
+	series := MatchSeries{
+		Player1:         cl.Player1,
+		Player2:         cl.Player2,
+		Player1Name:     cl.Player1Name,
+		Player2Name:     cl.Player2Name,
+		Repetitions:     cl.RepeatGames,
+		MoveTimeout:     cl.MoveTimeout,
+		HardMoveTimeout: cl.HardMoveTimeout,
+		LogTo:           os.Stdout,
+		OnGame: func(g *SeriesGame) {
+			for _, l := range gameLogs {
+				if err := l.WriteGame(g); err != nil {
+					fmt.Fprintf(os.Stderr, "cannot write game log: %s\n", err)
+				}
+			}
+		},
+	}
+	result := series.Run()
 	result.Print(os.Stdout)
 	os.Stdout.Sync()
+	if cl.JSONSummaryTo != "" {
+		if err := saveBotMatchSummary(cl.JSONSummaryTo, &series, &result); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot save summary: %s\n", err)
+			os.Exit(2)
+		}
+	}
+}
+
+type botMatchSummary struct {
+	Player1         string            `json:"player1"`
+	Player2         string            `json:"player2"`
+	Games           uint              `json:"games"`
+	MoveTimeout     time.Duration     `json:"moveTimeoutNs"`
+	HardMoveTimeout time.Duration     `json:"hardMoveTimeoutNs"`
+	Result          *RepeatGameResult `json:"result"`
+}
+
+func saveBotMatchSummary(path string, series *MatchSeries, result *RepeatGameResult) (err error) {
+	var f *os.File
+	if f, err = os.Create(path); err != nil {
+		return
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(&botMatchSummary{
+		Player1:         series.Player1Name,
+		Player2:         series.Player2Name,
+		Games:           series.Repetitions,
+		MoveTimeout:     series.MoveTimeout,
+		HardMoveTimeout: series.HardMoveTimeout,
+		Result:          result,
+	}); err != nil {
+		f.Close()
+		return
+	}
+	return f.Close()
 }
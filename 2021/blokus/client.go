@@ -22,6 +22,9 @@ type Client struct {
 	State           MutableState
 	ReservationCode string
 	DebugTo         *os.File
+	// Record is filled with the moves of the game, if set. Moves of the other player are inferred from the
+	// states sent by the server.
+	Record *GameRecord
 }
 
 func OpenClient(address net.Addr, player Player, emptyState MutableState) (cl *Client, err error) {
@@ -67,7 +70,8 @@ func (c *Client) Run() (err error) {
 	}
 	var roomID string
 	var isFirstPlayer bool
-	if roomID, isFirstPlayer, err = xc.join(c.ReservationCode, c.State); err != nil {
+	var initialState *protocol.State
+	if roomID, isFirstPlayer, initialState, err = xc.join(c.ReservationCode, c.State); err != nil {
 		err = fmt.Errorf("cannot join game: %s", err)
 		return
 	}
@@ -82,9 +86,10 @@ func (c *Client) Run() (err error) {
 	}
 	var gameEnded bool
 	var validColors map[Color]bool
+	rec := newClientRecorder(c.Record, colors, c.State, initialState)
 
 	for colorIdx := 0; ; colorIdx = (colorIdx + 1) % len(colors) {
-		if gameEnded, validColors, err = xc.waitForMoveRequest(roomID, c.State); err != nil || gameEnded {
+		if gameEnded, validColors, err = xc.waitForMoveRequest(roomID, c.State, rec); err != nil || gameEnded {
 			return
 		}
 		playerColor := colors[colorIdx]
@@ -97,7 +102,9 @@ func (c *Client) Run() (err error) {
 				continue
 			}
 		}
+		startT := time.Now()
 		move := c.Player.NextMove(c.State, playerColor, sc.NewTimeout(moveTimeout))
+		rec.ownMove(playerColor, move, time.Since(startT))
 		if err = xc.sendMove(roomID, colors[colorIdx], move); err != nil {
 			err = fmt.Errorf("cannot send move: %s", err)
 			return
@@ -105,6 +112,64 @@ func (c *Client) Run() (err error) {
 	}
 }
 
+// clientRecorder fills a GameRecord while a Client is running. All methods are no-ops if the record is nil.
+type clientRecorder struct {
+	record    *GameRecord
+	ownColors [2]Color
+	last      BasicState
+	lastValid map[Color]bool
+}
+
+func newClientRecorder(record *GameRecord, ownColors [2]Color, s State, xs *protocol.State) *clientRecorder {
+	r := &clientRecorder{
+		record:    record,
+		ownColors: ownColors,
+	}
+	if record == nil {
+		return r
+	}
+	*record = GameRecord{
+		StartPiece:  s.StartPiece(),
+		Player1Name: xs.FirstTeam.DisplayName,
+		Player2Name: xs.SecondTeam.DisplayName,
+	}
+	CopyState(&r.last, s)
+	r.lastValid = map[Color]bool{ColorBlue: true, ColorYellow: true, ColorRed: true, ColorGreen: true}
+	return r
+}
+
+func (r *clientRecorder) isOwnColor(c Color) bool {
+	return c == r.ownColors[0] || c == r.ownColors[1]
+}
+
+func (r *clientRecorder) ownMove(c Color, m Move, thinkTime time.Duration) {
+	if r.record == nil {
+		return
+	}
+	r.record.Entries = append(r.record.Entries, GameRecordEntry{Color: c, Move: m, ThinkTime: thinkTime})
+}
+
+// stateReceived records the moves of the other player's colors, inferred from the difference to the last state received
+func (r *clientRecorder) stateReceived(s State, validColors map[Color]bool) {
+	if r.record == nil {
+		return
+	}
+	for _, e := range inferEntries(&r.last, r.lastValid, s, validColors) {
+		if !r.isOwnColor(e.Color) {
+			r.record.Entries = append(r.record.Entries, e)
+		}
+	}
+	CopyState(&r.last, s)
+	r.lastValid = validColors
+}
+
+func (r *clientRecorder) gameEnded() {
+	if r.record == nil {
+		return
+	}
+	r.record.setResultFrom(&r.last)
+}
+
 type xmlConn struct {
 	enc *xml.Encoder
 	dec *xml.Decoder
@@ -149,7 +214,7 @@ func (x *xmlConn) receive(v interface{}) error {
 	return x.dec.Decode(v)
 }
 
-func (x *xmlConn) join(reservationCode string, intoState MutableState) (roomID string, isFirstPlayer bool, err error) {
+func (x *xmlConn) join(reservationCode string, intoState MutableState) (roomID string, isFirstPlayer bool, initialState *protocol.State, err error) {
 	if reservationCode != "" {
 		if err = x.sendBytes(protocol.JoinPreparedMessage(reservationCode)); err != nil {
 			return
@@ -172,7 +237,7 @@ func (x *xmlConn) join(reservationCode string, intoState MutableState) (roomID s
 	if err = x.receive(&welcomeMessage); err != nil {
 		return
 	}
-	isFirstPlayer = welcomeMessage.Data.ColorAttr == "ONE"
+	isFirstPlayer = welcomeMessage.Data.ColorAttr == protocol.TeamOne
 
 	var stateInRoom protocol.Room
 	if err = x.receive(&stateInRoom); err != nil {
@@ -181,6 +246,7 @@ func (x *xmlConn) join(reservationCode string, intoState MutableState) (roomID s
 	if _, err = fillState(intoState, stateInRoom.Data.State); err != nil {
 		return
 	}
+	initialState = stateInRoom.Data.State
 	return
 }
 
@@ -200,7 +266,7 @@ func (x *xmlConn) expectProtocol() error {
 	return nil
 }
 
-func (x *xmlConn) waitForMoveRequest(roomID string, intoState MutableState) (gameEnded bool, validColors map[Color]bool, err error) {
+func (x *xmlConn) waitForMoveRequest(roomID string, intoState MutableState, rec *clientRecorder) (gameEnded bool, validColors map[Color]bool, err error) {
 	for {
 		var room protocol.Room
 		if err = x.receive(&room); err != nil {
@@ -216,8 +282,10 @@ func (x *xmlConn) waitForMoveRequest(roomID string, intoState MutableState) (gam
 			if err != nil {
 				return
 			}
+			rec.stateReceived(intoState, validColors)
 		case protocol.DataClassResult:
 			gameEnded = true
+			rec.gameEnded()
 			return
 		case protocol.DataClassMoveRequest:
 			return
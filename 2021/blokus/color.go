@@ -29,6 +29,11 @@ func (c Color) String() string {
 	}
 }
 
+// Letter returns the first letter of the color name, as used in plain text output
+func (c Color) Letter() byte {
+	return c.String()[0]
+}
+
 func ParseColor(s string) (c Color, err error) {
 	switch strings.TrimSpace(s) {
 	case "BLUE":
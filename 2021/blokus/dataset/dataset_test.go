@@ -0,0 +1,131 @@
+package dataset
+
+import (
+	"bytes"
+	"github.com/hschendel/sc"
+	"github.com/hschendel/sc/2021/blokus"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// firstMovePlayer always plays the first possible move
+type firstMovePlayer struct{}
+
+func (firstMovePlayer) NextMove(state blokus.State, color blokus.Color, timeout sc.Timeout) blokus.Move {
+	moves := blokus.PossibleNextMoves(state, color)
+	if len(moves) == 0 {
+		return blokus.EmptyMove
+	}
+	return moves[0]
+}
+
+func (firstMovePlayer) End() {
+}
+
+func TestRecord_WriteRead(t *testing.T) {
+	g := blokus.NewGame(new(blokus.BasicState), blokus.PiecePentoL)
+	for i := 0; i < 5; i++ {
+		if err := g.Play(g.LegalMoves()[0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records := []Record{
+		NewRecord(g.State(), g.CurrentColor(), 5, g.LegalMoves()[0], -12),
+		NewRecord(g.State(), g.CurrentColor(), 5, blokus.EmptyMove, 3),
+	}
+	records[1].Symmetry = blokus.SymmetryRotateLeft
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range records {
+		if err = w.Write(&records[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if buf.Len() != headerSize+len(records)*RecordSize {
+		t.Errorf("expected %d bytes but got %d", headerSize+len(records)*RecordSize, buf.Len())
+	}
+	rd, err := NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range records {
+		var r Record
+		if err = rd.Read(&r); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(r, records[i]) {
+			t.Errorf("record %d: expected %+v but got %+v", i, records[i], r)
+		}
+	}
+	var r Record
+	if err = rd.Read(&r); err != io.EOF {
+		t.Errorf("expected EOF but got %v", err)
+	}
+	// the record does not contain the start piece and the color to move, so only compare board and pieces
+	expected := strings.Fields(blokus.FormatPosition(g.State()))[:2]
+	if got := strings.Fields(blokus.FormatPosition(records[0].State()))[:2]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected the decoded state to be %v but got %v", expected, got)
+	}
+}
+
+func TestNewReader_NoDataset(t *testing.T) {
+	if _, err := NewReader(bytes.NewReader(make([]byte, headerSize))); err != ErrNoDataset {
+		t.Errorf("expected ErrNoDataset but got %v", err)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	s := blokus.NewGame(new(blokus.BasicState), blokus.PiecePentoL).State()
+	r := NewRecord(s, blokus.ColorBlue, 0, blokus.EmptyMove, 10)
+	d := make(dedup)
+	if d.add(&r) {
+		t.Errorf("expected the first record not to be a duplicate")
+	}
+	r.ScoreDiff, r.Symmetry = -10, blokus.SymmetryRotate180
+	if !d.add(&r) {
+		t.Errorf("expected a record that only differs in outcome and symmetry to be a duplicate")
+	}
+	r.Turn = 4
+	if d.add(&r) {
+		t.Errorf("expected a record of a different turn not to be a duplicate")
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := Generator{Player1: firstMovePlayer{}, Player2: firstMovePlayer{}, Games: 1, Seed: 1, Augment: true}
+	stats, err := g.Run(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Games != 1 || stats.Positions == 0 || stats.Records != stats.Positions*blokus.NumSymmetries {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	rd, err := NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint(0); i < stats.Records; i++ {
+		var r Record
+		if err = rd.Read(&r); err != nil {
+			t.Fatal(err)
+		}
+		if expected := blokus.Symmetry(i % blokus.NumSymmetries); r.Symmetry != expected {
+			t.Fatalf("record %d: expected symmetry %s but got %s", i, expected.String(), r.Symmetry.String())
+		}
+		// the start piece is not part of the record, so the first moves cannot be checked
+		s := r.State()
+		if s.HasPlayed(r.Color) && !blokus.CanApplyMove(s, r.Color, r.Move) {
+			t.Fatalf("record %d: move %s of %s is not possible", i, r.Move.String(), r.Color.String())
+		}
+	}
+}
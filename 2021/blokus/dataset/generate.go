@@ -0,0 +1,113 @@
+package dataset
+
+import (
+	"fmt"
+	"github.com/hschendel/sc/2021/blokus"
+	"hash/fnv"
+	"io"
+	"time"
+)
+
+// Generator plays games between two players and writes a Record for every move played
+type Generator struct {
+	Player1     blokus.Player
+	Player2     blokus.Player
+	Player1Name string
+	Player2Name string
+	Games       uint
+	// Seed determines the start pieces, see blokus.MatchSeries
+	Seed        int64
+	MoveTimeout time.Duration
+	// Augment adds the records of all other symmetries of every position
+	Augment bool
+	// Dedup skips records of positions and moves that were written before, no matter the symmetry or the outcome
+	Dedup bool
+	// LogTo receives the result of every game, if set
+	LogTo io.Writer
+}
+
+// GeneratorStats counts what a Generator did
+type GeneratorStats struct {
+	Games      uint
+	Positions  uint
+	Records    uint
+	Duplicates uint
+}
+
+// Run plays the games and writes the records to w. It stops at the first error writing a record or replaying a game.
+func (g *Generator) Run(w *Writer) (stats GeneratorStats, err error) {
+	seen := make(dedup)
+	write := func(r *Record) error {
+		if g.Dedup && seen.add(r) {
+			stats.Duplicates++
+			return nil
+		}
+		stats.Records++
+		return w.Write(r)
+	}
+	series := blokus.MatchSeries{
+		Player1:     g.Player1,
+		Player2:     g.Player2,
+		Player1Name: g.Player1Name,
+		Player2Name: g.Player2Name,
+		Repetitions: g.Games,
+		Seed:        g.Seed,
+		MoveTimeout: g.MoveTimeout,
+		LogTo:       g.LogTo,
+	}
+	series.OnGame = func(sg *blokus.SeriesGame) {
+		if err != nil {
+			return
+		}
+		stats.Games++
+		var states []*blokus.BasicState
+		if states, err = blokus.Replay(sg.Record); err != nil {
+			err = fmt.Errorf("cannot replay game %d: %s", sg.Number, err)
+			return
+		}
+		final := states[len(states)-1]
+		score1 := int16(blokus.OfficialRatingForPlayer(final, true))
+		score2 := int16(blokus.OfficialRatingForPlayer(final, false))
+		var ts blokus.BasicState
+		for i, e := range sg.Record.Entries {
+			stats.Positions++
+			scoreDiff := score1 - score2
+			if e.Color%2 != 0 {
+				scoreDiff = -scoreDiff
+			}
+			r := NewRecord(states[i], e.Color, uint8(i), e.Move, scoreDiff)
+			if err = write(&r); err != nil {
+				return
+			}
+			if !g.Augment {
+				continue
+			}
+			for sym := blokus.Symmetry(1); sym < blokus.NumSymmetries; sym++ {
+				blokus.TransformState(&ts, states[i], sym)
+				r = NewRecord(&ts, e.Color, uint8(i), sym.Move(e.Move), scoreDiff)
+				r.Symmetry = sym
+				if err = write(&r); err != nil {
+					return
+				}
+			}
+		}
+	}
+	series.Run()
+	return
+}
+
+// dedup contains hashes of the records written
+type dedup map[uint64]bool
+
+// add returns true if a record with the same position and move was added before
+func (d dedup) add(r *Record) (duplicate bool) {
+	var buf [RecordSize]byte
+	r.encode(buf[:])
+	h := fnv.New64a()
+	// the symmetry and the score diff are not part of the key
+	h.Write(buf[:221])
+	key := h.Sum64()
+	duplicate = d[key]
+	d[key] = true
+	return
+}
@@ -0,0 +1,233 @@
+// Package dataset writes positions from self-play games as training records for offline learning.
+//
+// A dataset file starts with a 16 byte header: the magic "BLOKUSDS", the format version and the record size, both as
+// little endian uint32. It is followed by records of RecordSize bytes each, all numbers little endian:
+//
+//	offset  size  field
+//	     0   200  planes: 4 bit planes of 400 bits, one per color; bit y*20+x of plane c is byte (y*20+x)/8,
+//	              bit (y*20+x)%8, and it is set if c covers the cell x,y
+//	   200    16  remaining: 4 uint32, one per color; bit p is set if piece p is not played yet
+//	   216     1  color: the color to move
+//	   217     1  turn: the number of moves played before, including skipped ones
+//	   218     1  piece: the transformed piece of the move played, see blokus.TransformedPiece, or 255 if the
+//	              color skipped
+//	   219     1  x of the move
+//	   220     1  y of the move
+//	   221     1  symmetry: the blokus.Symmetry applied to the original position, 0 for none
+//	   222     2  score diff: int16, the final score of the player owning color minus the final score of the
+//	              other player, see blokus.OfficialRatingForPlayer
+//
+// With NumPy, a file can be read like this:
+//
+//	dtype = np.dtype([("planes", "u1", (4, 50)), ("remaining", "<u4", 4), ("color", "u1"), ("turn", "u1"),
+//	                  ("piece", "u1"), ("x", "u1"), ("y", "u1"), ("symmetry", "u1"), ("score_diff", "<i2")])
+//	records = np.fromfile("games.bin", dtype=dtype, offset=16)
+//	planes = np.unpackbits(records["planes"], axis=2, bitorder="little").reshape(-1, 4, 20, 20)
+package dataset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/hschendel/sc/2021/blokus"
+	"io"
+)
+
+// FormatVersion is written into the header of every dataset file
+const FormatVersion = 1
+
+// RecordSize is the number of bytes of an encoded Record
+const RecordSize = 224
+
+const headerSize = 16
+
+const noMove = 0xFF
+
+var magic = [8]byte{'B', 'L', 'O', 'K', 'U', 'S', 'D', 'S'}
+
+// Record is a position, the move played in it, and the outcome of the game
+type Record struct {
+	// Planes has bit y*20+x of Planes[c] set if color c covers x,y
+	Planes [4][50]byte
+	// Remaining has bit p of Remaining[c] set if color c has not played piece p yet
+	Remaining [4]uint32
+	Color     blokus.Color
+	Turn      uint8
+	Move      blokus.Move
+	Symmetry  blokus.Symmetry
+	ScoreDiff int16
+}
+
+// NewRecord encodes the position s in which c played m
+func NewRecord(s blokus.State, c blokus.Color, turn uint8, m blokus.Move, scoreDiff int16) (r Record) {
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			if oc, hasPiece := s.At(x, y); hasPiece {
+				i := int(y)*20 + int(x)
+				r.Planes[oc][i/8] |= 1 << (i % 8)
+			}
+		}
+	}
+	for oc := blokus.Color(0); oc < 4; oc++ {
+		for _, p := range s.NotPlayedPiecesFor(oc) {
+			r.Remaining[oc] |= 1 << p
+		}
+	}
+	r.Color = c
+	r.Turn = turn
+	r.Move = m
+	r.ScoreDiff = scoreDiff
+	return
+}
+
+// State returns the position of r. It does not know whether the last move of a color was the mono.
+func (r *Record) State() (s *blokus.BasicState) {
+	s = new(blokus.BasicState)
+	s.Reset()
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			for c := blokus.Color(0); c < 4; c++ {
+				if r.Covers(c, x, y) {
+					s.Set(x, y, c, true)
+				}
+			}
+		}
+	}
+	for c := blokus.Color(0); c < 4; c++ {
+		var pieces []blokus.Piece
+		for p := blokus.PieceMono; p < blokus.NumPieces; p++ {
+			if r.Remaining[c]&(1<<p) != 0 {
+				pieces = append(pieces, p)
+			}
+		}
+		s.SetNotPlayedPiecesFor(c, pieces)
+	}
+	return
+}
+
+// Covers returns true if c covers x,y
+func (r *Record) Covers(c blokus.Color, x, y uint8) bool {
+	i := int(y)*20 + int(x)
+	return r.Planes[c][i/8]&(1<<(i%8)) != 0
+}
+
+// MarshalBinary encodes r in the format described in the package documentation
+func (r *Record) MarshalBinary() ([]byte, error) {
+	b := make([]byte, RecordSize)
+	r.encode(b)
+	return b, nil
+}
+
+func (r *Record) encode(b []byte) {
+	for c := range r.Planes {
+		copy(b[c*50:], r.Planes[c][:])
+	}
+	for c, remaining := range r.Remaining {
+		binary.LittleEndian.PutUint32(b[200+c*4:], remaining)
+	}
+	b[216] = uint8(r.Color)
+	b[217] = r.Turn
+	if r.Move.IsEmpty() {
+		b[218], b[219], b[220] = noMove, 0, 0
+	} else {
+		b[218], b[219], b[220] = uint8(r.Move.Transformation), r.Move.X, r.Move.Y
+	}
+	b[221] = uint8(r.Symmetry)
+	binary.LittleEndian.PutUint16(b[222:], uint16(r.ScoreDiff))
+}
+
+// UnmarshalBinary decodes a record encoded by MarshalBinary
+func (r *Record) UnmarshalBinary(b []byte) error {
+	if len(b) != RecordSize {
+		return fmt.Errorf("expected %d bytes but got %d", RecordSize, len(b))
+	}
+	for c := range r.Planes {
+		copy(r.Planes[c][:], b[c*50:])
+	}
+	for c := range r.Remaining {
+		r.Remaining[c] = binary.LittleEndian.Uint32(b[200+c*4:])
+	}
+	if b[216] > 3 {
+		return fmt.Errorf("invalid color %d", b[216])
+	}
+	r.Color = blokus.Color(b[216])
+	r.Turn = b[217]
+	if b[218] == noMove {
+		r.Move = blokus.EmptyMove
+	} else {
+		tp := blokus.TransformedPiece(b[218])
+		if tp.Piece() >= blokus.NumPieces {
+			return fmt.Errorf("invalid piece %d", tp.Piece())
+		}
+		r.Move = blokus.NewMove(tp, b[219], b[220])
+	}
+	if b[221] >= blokus.NumSymmetries {
+		return fmt.Errorf("invalid symmetry %d", b[221])
+	}
+	r.Symmetry = blokus.Symmetry(b[221])
+	r.ScoreDiff = int16(binary.LittleEndian.Uint16(b[222:]))
+	return nil
+}
+
+// Writer writes a dataset file
+type Writer struct {
+	w   io.Writer
+	buf [RecordSize]byte
+}
+
+// NewWriter writes the header to w, and returns a Writer for the records
+func NewWriter(w io.Writer) (*Writer, error) {
+	var header [headerSize]byte
+	copy(header[:], magic[:])
+	binary.LittleEndian.PutUint32(header[8:], FormatVersion)
+	binary.LittleEndian.PutUint32(header[12:], RecordSize)
+	if _, err := w.Write(header[:]); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w}, nil
+}
+
+func (w *Writer) Write(r *Record) (err error) {
+	r.encode(w.buf[:])
+	_, err = w.w.Write(w.buf[:])
+	return
+}
+
+// ErrNoDataset is returned by NewReader if the header is not the one of a dataset file
+var ErrNoDataset = errors.New("not a dataset file")
+
+// Reader reads a dataset file
+type Reader struct {
+	r   io.Reader
+	buf [RecordSize]byte
+}
+
+// NewReader checks the header of the dataset in r, and returns a Reader for the records
+func NewReader(r io.Reader) (*Reader, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:8], magic[:]) {
+		return nil, ErrNoDataset
+	}
+	if version := binary.LittleEndian.Uint32(header[8:]); version != FormatVersion {
+		return nil, fmt.Errorf("unsupported dataset format version %d", version)
+	}
+	if size := binary.LittleEndian.Uint32(header[12:]); size != RecordSize {
+		return nil, fmt.Errorf("unexpected record size %d", size)
+	}
+	return &Reader{r: r}, nil
+}
+
+// Read reads the next record into r. It returns io.EOF if there are no more records.
+func (rd *Reader) Read(r *Record) (err error) {
+	if _, err = io.ReadFull(rd.r, rd.buf[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = fmt.Errorf("incomplete record at end of dataset")
+		}
+		return
+	}
+	return r.UnmarshalBinary(rd.buf[:])
+}
@@ -0,0 +1,271 @@
+// Package endgame solves late positions exactly for a single color. The free cells a color can still cover fall
+// apart into regions that cannot influence each other, so every region is searched on its own, and the results are
+// combined so that each piece is used at most once, maximizing the points of blokus.OfficialRatingForColor.
+//
+// The solver only looks at the moves of one color. Its result is optimal if the other colors cannot enter the
+// regions anymore, see eval.Territory.
+package endgame
+
+import (
+	"github.com/hschendel/sc/2021/blokus"
+	"time"
+)
+
+// Solution is the result of Solve
+type Solution struct {
+	// Moves is the best sequence of moves found, starting with the move to play next. It is empty if the color
+	// cannot move.
+	Moves []blokus.Move
+	// Points is the number of points Moves add to the rating of the color, including the bonus points
+	Points uint
+	// Complete is false if the search did not finish within the time budget. Moves is the best sequence found
+	// until then.
+	Complete bool
+	// Regions is the number of independent regions the color can still place pieces in
+	Regions int
+	// Nodes is the number of positions searched
+	Nodes uint64
+}
+
+// Solve searches the moves of c that add the most points to its rating, and stops after budget. It does not solve
+// positions in which c has not played yet.
+func Solve(s blokus.State, c blokus.Color, budget time.Duration) (sol Solution) {
+	if !s.HasPlayed(c) {
+		return
+	}
+	sv := solver{c: c, deadline: time.Now().Add(budget)}
+	blokus.CopyState(&sv.s, s)
+	regions := sv.findRegions()
+	sol.Regions = len(regions)
+	for _, r := range regions {
+		sv.searchRegion(r)
+	}
+	var remaining uint32
+	for _, p := range s.NotPlayedPiecesFor(c) {
+		remaining |= 1 << p
+	}
+	sol.Moves, sol.Points = sv.combine(regions, remaining)
+	sol.Complete = !sv.aborted
+	sol.Nodes = sv.nodes
+	return
+}
+
+type solver struct {
+	s        blokus.BasicState
+	c        blokus.Color
+	deadline time.Time
+	// regionOf contains the index of the region of a cell plus 1, or 0 if c cannot cover it
+	regionOf [20][20]int
+	nodes    uint64
+	aborted  bool
+}
+
+// region is a set of free cells c can cover, connected horizontally, vertically or diagonally. A piece placed in a
+// region only changes which cells of the same region c can cover.
+type region struct {
+	index int
+	// achievable contains the placements found for each set of pieces, as bit mask of the pieces
+	achievable map[uint32]*placements
+}
+
+// placements are sequences of moves placing a set of pieces in a region
+type placements struct {
+	moves []blokus.Move
+	// monoLast ends with the mono, it is nil if no such sequence was found
+	monoLast []blokus.Move
+}
+
+func (sv *solver) canCover(x, y uint8) bool {
+	_, hasPiece := sv.s.At(x, y)
+	return !hasPiece && !blokus.HasDirectNeighborWithColor(&sv.s, sv.c, x, y)
+}
+
+// findRegions returns the regions that contain an anchor of c. Regions without anchors cannot be reached, as
+// placing a piece only creates anchors in its own region.
+func (sv *solver) findRegions() (regions []*region) {
+	for _, a := range blokus.Anchors(&sv.s, sv.c) {
+		if sv.regionOf[a.Y][a.X] != 0 {
+			continue
+		}
+		r := &region{index: len(regions), achievable: make(map[uint32]*placements)}
+		regions = append(regions, r)
+		sv.regionOf[a.Y][a.X] = r.index + 1
+		stack := []blokus.Position{a}
+		for len(stack) > 0 {
+			p := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for dy := int8(-1); dy <= 1; dy++ {
+				for dx := int8(-1); dx <= 1; dx++ {
+					x, y := uint8(int8(p.X)+dx), uint8(int8(p.Y)+dy)
+					if x >= 20 || y >= 20 || sv.regionOf[y][x] != 0 || !sv.canCover(x, y) {
+						continue
+					}
+					sv.regionOf[y][x] = r.index + 1
+					stack = append(stack, blokus.Position{X: x, Y: y})
+				}
+			}
+		}
+	}
+	return
+}
+
+// searchKey identifies a position in a region by the pieces placed and the cells covered
+type searchKey struct {
+	pieces uint32
+	cells  [7]uint64
+}
+
+func (sv *solver) searchRegion(r *region) {
+	var key searchKey
+	visited := make(map[searchKey]bool)
+	sv.search(r, &key, nil, visited)
+}
+
+func (sv *solver) search(r *region, key *searchKey, path []blokus.Move, visited map[searchKey]bool) {
+	if sv.aborted {
+		return
+	}
+	sv.nodes++
+	if sv.nodes%256 == 0 && time.Now().After(sv.deadline) {
+		sv.aborted = true
+		return
+	}
+	pl := r.achievable[key.pieces]
+	if pl == nil {
+		pl = &placements{moves: append([]blokus.Move(nil), path...)}
+		r.achievable[key.pieces] = pl
+	}
+	if pl.monoLast == nil && len(path) > 0 && path[len(path)-1].Transformation.Piece() == blokus.PieceMono {
+		pl.monoLast = append([]blokus.Move(nil), path...)
+	}
+	// the sequence only matters for the mono bonus, which is recorded above
+	if visited[*key] {
+		return
+	}
+	visited[*key] = true
+
+	for _, m := range blokus.PossibleNextMoves(&sv.s, sv.c) {
+		if !sv.inRegion(m, r) {
+			continue
+		}
+		blokus.MustApplyMove(&sv.s, sv.c, m)
+		sv.toggleCells(key, m)
+		key.pieces |= 1 << m.Transformation.Piece()
+		sv.search(r, key, append(path, m), visited)
+		key.pieces &^= 1 << m.Transformation.Piece()
+		sv.toggleCells(key, m)
+		blokus.UndoMove(&sv.s, sv.c, m)
+	}
+}
+
+func (sv *solver) inRegion(m blokus.Move, r *region) bool {
+	for _, p := range m.Transformation.Positions() {
+		if sv.regionOf[m.Y+p.Y][m.X+p.X] != r.index+1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (sv *solver) toggleCells(key *searchKey, m blokus.Move) {
+	for _, p := range m.Transformation.Positions() {
+		i := int(m.Y+p.Y)*20 + int(m.X+p.X)
+		key.cells[i/64] ^= 1 << (i % 64)
+	}
+}
+
+// choice selects the placements of a set of pieces in a region
+type choice struct {
+	region   *region
+	pieces   uint32
+	monoLast bool
+}
+
+type combination struct {
+	choices  []choice
+	monoLast bool
+}
+
+// combine selects placements in all regions that use every piece at most once and give the most points. remaining
+// contains the pieces c has not played yet. After the deadline, only the placements with the most points that fit
+// are added for the remaining regions.
+func (sv *solver) combine(regions []*region, remaining uint32) (moves []blokus.Move, points uint) {
+	const mono = 1 << blokus.PieceMono
+	combinations := map[uint32]*combination{0: {}}
+	for _, r := range regions {
+		next := make(map[uint32]*combination, len(combinations))
+		for used, cb := range combinations {
+			keepBetter(next, used, cb)
+			late := sv.aborted || time.Now().After(sv.deadline)
+			sv.aborted = late
+			var bestPieces uint32
+			for pieces, pl := range r.achievable {
+				if pieces == 0 || pieces&used != 0 {
+					continue
+				}
+				if late {
+					if rating(pieces, 0, false) > rating(bestPieces, 0, false) {
+						bestPieces = pieces
+					}
+					continue
+				}
+				ch := choice{region: r, pieces: pieces, monoLast: pieces&mono != 0 && pl.monoLast != nil}
+				keepBetter(next, used|pieces, &combination{
+					choices:  append(cb.choices[:len(cb.choices):len(cb.choices)], ch),
+					monoLast: cb.monoLast || ch.monoLast,
+				})
+			}
+			if bestPieces != 0 {
+				keepBetter(next, used|bestPieces, &combination{
+					choices:  append(cb.choices[:len(cb.choices):len(cb.choices)], choice{region: r, pieces: bestPieces}),
+					monoLast: cb.monoLast,
+				})
+			}
+		}
+		combinations = next
+	}
+
+	var best *combination
+	var bestUsed uint32
+	for used, cb := range combinations {
+		p := rating(used, remaining, cb.monoLast)
+		if best == nil || p > points || (p == points && used < bestUsed) {
+			best, bestUsed, points = cb, used, p
+		}
+	}
+	useMonoLast := bestUsed == remaining && best.monoLast
+	var last []blokus.Move
+	for _, ch := range best.choices {
+		pl := ch.region.achievable[ch.pieces]
+		if useMonoLast && ch.monoLast {
+			last = pl.monoLast
+			continue
+		}
+		moves = append(moves, pl.moves...)
+	}
+	moves = append(moves, last...)
+	return
+}
+
+// keepBetter stores cb for used, unless there already is a combination for used that is at least as good
+func keepBetter(combinations map[uint32]*combination, used uint32, cb *combination) {
+	if existing := combinations[used]; existing == nil || (cb.monoLast && !existing.monoLast) {
+		combinations[used] = cb
+	}
+}
+
+// rating returns the points of placing the pieces used, with the bonus points of blokus.OfficialRatingForColor
+func rating(used, remaining uint32, monoLast bool) (points uint) {
+	for p := blokus.PieceMono; p < blokus.NumPieces; p++ {
+		if used&(1<<p) != 0 {
+			points += p.NumPoints()
+		}
+	}
+	if used == remaining && remaining != 0 {
+		points += blokus.AdditionalPointsIfAllPiecesPlayed
+		if monoLast {
+			points += blokus.AdditionalPointsIfLastMoveMono
+		}
+	}
+	return
+}
@@ -0,0 +1,99 @@
+package endgame
+
+import (
+	"github.com/hschendel/sc"
+	"github.com/hschendel/sc/2021/blokus"
+	"testing"
+	"time"
+)
+
+// pocketState returns a board filled with green, except for a diagonal line of blue cells from 0,0 to 10,10, and two
+// pockets next to it: the single cell 0,2 and the two cells 11,11 and 12,11. Blue has the MONO and the DOMINO left.
+func pocketState() *blokus.BasicState {
+	var s blokus.BasicState
+	s.Reset()
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			s.Set(x, y, blokus.ColorGreen, true)
+		}
+	}
+	for _, p := range []blokus.Position{{X: 0, Y: 2}, {X: 11, Y: 11}, {X: 12, Y: 11}} {
+		s.Set(p.X, p.Y, blokus.ColorGreen, false)
+	}
+	for i := uint8(0); i <= 10; i++ {
+		s.Set(i, i, blokus.ColorBlue, true)
+	}
+	s.SetNotPlayedPiecesFor(blokus.ColorBlue, []blokus.Piece{blokus.PieceMono, blokus.PieceDomino})
+	return &s
+}
+
+func TestSolve(t *testing.T) {
+	s := pocketState()
+	sol := Solve(s, blokus.ColorBlue, time.Second)
+	if !sol.Complete || sol.Regions != 2 {
+		t.Fatalf("expected complete solution with 2 regions but got %+v", sol)
+	}
+	// the DOMINO only fits into the larger pocket, so the MONO must go to the smaller one, and be played last
+	if sol.Points != 1+2+blokus.AdditionalPointsIfAllPiecesPlayed+blokus.AdditionalPointsIfLastMoveMono {
+		t.Errorf("expected all pieces with mono bonus, but got %d points", sol.Points)
+	}
+	expected := []blokus.Move{blokus.MustParseMove("DOMINO:NONE@11,11"), blokus.MustParseMove("MONO:NONE@0,2")}
+	if !blokus.MovesEqual(sol.Moves, expected) || !sol.Moves[1].Equal(expected[1]) {
+		t.Fatalf("expected moves %v but got %v", expected, sol.Moves)
+	}
+	before := blokus.OfficialRatingForColor(s, blokus.ColorBlue)
+	for _, m := range sol.Moves {
+		blokus.MustApplyMove(s, blokus.ColorBlue, m)
+	}
+	if gain := blokus.OfficialRatingForColor(s, blokus.ColorBlue) - before; gain != sol.Points {
+		t.Errorf("expected the solution to add %d to the rating, but it added %d", sol.Points, gain)
+	}
+}
+
+func TestSolve_Budget(t *testing.T) {
+	var s blokus.BasicState
+	s.Reset()
+	s.Set(0, 0, blokus.ColorBlue, true)
+	s.SetPiecePlayed(blokus.ColorBlue, blokus.PieceMono, true)
+	sol := Solve(&s, blokus.ColorBlue, 10*time.Millisecond)
+	if sol.Complete {
+		t.Fatalf("expected the search on an empty board not to complete")
+	}
+	if len(sol.Moves) == 0 || !blokus.CanApplyMove(&s, blokus.ColorBlue, sol.Moves[0]) {
+		t.Errorf("expected a playable move even without a complete search, but got %v", sol.Moves)
+	}
+}
+
+// countingPlayer skips, and counts how often it was asked for a move
+type countingPlayer struct {
+	calls int
+}
+
+func (c *countingPlayer) NextMove(state blokus.State, color blokus.Color, timeout sc.Timeout) blokus.Move {
+	c.calls++
+	return blokus.EmptyMove
+}
+
+func (c *countingPlayer) End() {
+}
+
+func TestPlayer(t *testing.T) {
+	fallback := new(countingPlayer)
+	p := Player{Player: fallback}
+	m := p.NextMove(pocketState(), blokus.ColorBlue, sc.NewTimeout(time.Second))
+	if fallback.calls != 0 || !m.Equal(blokus.MustParseMove("DOMINO:NONE@11,11")) {
+		t.Errorf("expected the solver to play DOMINO:NONE@11,11 but got %s, with %d fallback calls", m, fallback.calls)
+	}
+	if stats := p.LastSearchStats(); stats.Depth != 2 || stats.Nodes == 0 {
+		t.Errorf("unexpected search stats %+v", stats)
+	}
+
+	var s blokus.BasicState
+	s.Reset()
+	s.Set(0, 0, blokus.ColorBlue, true)
+	s.SetPiecePlayed(blokus.ColorBlue, blokus.PieceMono, true)
+	p.NextMove(&s, blokus.ColorBlue, sc.NewTimeout(time.Second))
+	if fallback.calls != 1 {
+		t.Errorf("expected the embedded player to decide with many legal moves")
+	}
+}
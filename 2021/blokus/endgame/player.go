@@ -0,0 +1,63 @@
+package endgame
+
+import (
+	"github.com/hschendel/sc"
+	"github.com/hschendel/sc/2021/blokus"
+	"time"
+)
+
+// DefaultThreshold is the default for Player.Threshold
+const DefaultThreshold = 20
+
+// DefaultBudget is the default for Player.Budget
+const DefaultBudget = 500 * time.Millisecond
+
+// Player plays the first move of the Solution of Solve once the color to move has at most Threshold legal moves.
+// Before that, and when Solve did not complete within its budget, the embedded Player decides.
+type Player struct {
+	blokus.Player
+	// Threshold is the number of legal moves below which the solver takes over, DefaultThreshold if zero
+	Threshold int
+	// Budget is the time the solver may use for a move, DefaultBudget if zero. It is limited to half of the time
+	// left for the move, so that the embedded Player still has time if the solver does not complete.
+	Budget time.Duration
+
+	lastSolved bool
+	lastStats  blokus.SearchStats
+}
+
+func (p *Player) NextMove(state blokus.State, color blokus.Color, timeout sc.Timeout) blokus.Move {
+	p.lastSolved = false
+	threshold := p.Threshold
+	if threshold == 0 {
+		threshold = DefaultThreshold
+	}
+	if state.HasPlayed(color) && blokus.CountPossibleNextMoves(state, color) <= threshold {
+		budget := p.Budget
+		if budget == 0 {
+			budget = DefaultBudget
+		}
+		if half := timeout.TimeLeft() / 2; budget > half {
+			budget = half
+		}
+		sol := Solve(state, color, budget)
+		if sol.Complete && len(sol.Moves) > 0 {
+			p.lastSolved = true
+			p.lastStats = blokus.SearchStats{Nodes: sol.Nodes, Depth: uint(len(sol.Moves))}
+			return sol.Moves[0]
+		}
+	}
+	return p.Player.NextMove(state, color, timeout)
+}
+
+// LastSearchStats reports the size of the search and the length of the solution if the solver picked the last move,
+// and otherwise the stats of the embedded Player, if it is a blokus.SearchStatsReporter
+func (p *Player) LastSearchStats() blokus.SearchStats {
+	if p.lastSolved {
+		return p.lastStats
+	}
+	if reporter, isReporter := p.Player.(blokus.SearchStatsReporter); isReporter {
+		return reporter.LastSearchStats()
+	}
+	return blokus.SearchStats{}
+}
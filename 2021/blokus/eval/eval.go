@@ -0,0 +1,222 @@
+// Package eval provides features of Blokus positions for a color, that can be combined into evaluation functions.
+// All features work on any blokus.State.
+package eval
+
+import (
+	"github.com/hschendel/sc/2021/blokus"
+)
+
+// Feature is a named feature of a position for a color
+type Feature struct {
+	Name  string
+	Value func(s blokus.State, c blokus.Color) float64
+}
+
+// All contains all features of this package. Features that are undefined for a color without pieces on the board
+// are 0 in that case.
+var All = []Feature{
+	{"cells", func(s blokus.State, c blokus.Color) float64 { return float64(Cells(s, c)) }},
+	{"bounding box area", func(s blokus.State, c blokus.Color) float64 { return float64(BoundingBoxArea(s, c)) }},
+	{"anchors", func(s blokus.State, c blokus.Color) float64 { return float64(AnchorCount(s, c)) }},
+	{"reachable area", func(s blokus.State, c blokus.Color) float64 { return float64(ReachableArea(s, c)) }},
+	{"legal moves", func(s blokus.State, c blokus.Color) float64 { return float64(LegalMoves(s, c)) }},
+	{"largest fitting piece", func(s blokus.State, c blokus.Color) float64 {
+		p, found := LargestFittingPiece(s, c)
+		if !found {
+			return 0
+		}
+		return float64(p.NumPoints())
+	}},
+	{"center distance", func(s blokus.State, c blokus.Color) float64 {
+		d, _ := CenterDistance(s, c)
+		return float64(d)
+	}},
+	{"enemy contacts", func(s blokus.State, c blokus.Color) float64 { return float64(EnemyContacts(s, c)) }},
+	{"exclusive territory", func(s blokus.State, c blokus.Color) float64 { return float64(AnalyzeTerritory(s).Exclusive(c)) }},
+	{"contested territory", func(s blokus.State, c blokus.Color) float64 { return float64(AnalyzeTerritory(s).Contested(c)) }},
+}
+
+// PlayerDiff returns the sum of f for both colors of the player owning c, minus the sum for both enemy colors
+func PlayerDiff(s blokus.State, c blokus.Color, f func(s blokus.State, c blokus.Color) float64) (diff float64) {
+	for _, own := range blokus.OwnColors(c) {
+		diff += f(s, own)
+	}
+	for _, enemy := range blokus.EnemyColors(c) {
+		diff -= f(s, enemy)
+	}
+	return
+}
+
+// Cells returns the number of cells covered by c
+func Cells(s blokus.State, c blokus.Color) (n int) {
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			if blokus.HasColorAt(s, c, x, y) {
+				n++
+			}
+		}
+	}
+	return
+}
+
+// Box is a rectangle on the board, including the cells at MinX,MinY and MaxX,MaxY
+type Box struct {
+	MinX uint8
+	MinY uint8
+	MaxX uint8
+	MaxY uint8
+}
+
+func (b Box) Width() uint8 {
+	return b.MaxX - b.MinX + 1
+}
+
+func (b Box) Height() uint8 {
+	return b.MaxY - b.MinY + 1
+}
+
+func (b Box) Area() int {
+	return int(b.Width()) * int(b.Height())
+}
+
+// BoundingBox returns the smallest Box containing all cells covered by c. found is false if c has not covered any
+// cells yet, then box is empty.
+func BoundingBox(s blokus.State, c blokus.Color) (box Box, found bool) {
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			if !blokus.HasColorAt(s, c, x, y) {
+				continue
+			}
+			if !found {
+				box = Box{MinX: x, MinY: y, MaxX: x, MaxY: y}
+				found = true
+				continue
+			}
+			if x < box.MinX {
+				box.MinX = x
+			}
+			if x > box.MaxX {
+				box.MaxX = x
+			}
+			box.MaxY = y
+		}
+	}
+	return
+}
+
+// BoundingBoxArea returns the area of the BoundingBox of c, or 0 if c has not covered any cells yet
+func BoundingBoxArea(s blokus.State, c blokus.Color) int {
+	box, found := BoundingBox(s, c)
+	if !found {
+		return 0
+	}
+	return box.Area()
+}
+
+// AnchorCount returns the number of cells where c could place its next piece, see blokus.IsAnchor
+func AnchorCount(s blokus.State, c blokus.Color) int {
+	return len(blokus.Anchors(s, c))
+}
+
+// ReachableArea returns the number of free cells c could still cover: cells that are not next to a cell of c, and
+// that are connected to an anchor of c through such cells. Unlike Territory, it does not consider the remaining
+// pieces of c.
+func ReachableArea(s blokus.State, c blokus.Color) (n int) {
+	var visited [20][20]bool
+	stack := blokus.Anchors(s, c)
+	for _, p := range stack {
+		visited[p.Y][p.X] = true
+	}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n++
+		for _, d := range [4][2]int8{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			x, y := uint8(int8(p.X)+d[0]), uint8(int8(p.Y)+d[1])
+			if x >= 20 || y >= 20 || visited[y][x] || !canCover(s, c, x, y) {
+				continue
+			}
+			visited[y][x] = true
+			stack = append(stack, blokus.Position{X: x, Y: y})
+		}
+	}
+	return
+}
+
+func canCover(s blokus.State, c blokus.Color, x, y uint8) bool {
+	_, hasPiece := s.At(x, y)
+	return !hasPiece && !blokus.HasDirectNeighborWithColor(s, c, x, y)
+}
+
+// LegalMoves returns the number of moves c can play
+func LegalMoves(s blokus.State, c blokus.Color) int {
+	return blokus.CountPossibleNextMoves(s, c)
+}
+
+// LargestFittingPiece returns the largest piece c can still play. found is false if c cannot play any piece.
+func LargestFittingPiece(s blokus.State, c blokus.Color) (p blokus.Piece, found bool) {
+	blokus.ForEachPossibleNextMove(s, c, func(m blokus.Move) bool {
+		mp := m.Transformation.Piece()
+		if !found || mp.NumPoints() > p.NumPoints() || (mp.NumPoints() == p.NumPoints() && mp < p) {
+			p = mp
+			found = true
+		}
+		return true
+	})
+	return
+}
+
+// CenterDistance returns the smallest Manhattan distance of a cell of c to the 2x2 cells in the center of the
+// board. found is false if c has not covered any cells yet.
+func CenterDistance(s blokus.State, c blokus.Color) (d int, found bool) {
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			if !blokus.HasColorAt(s, c, x, y) {
+				continue
+			}
+			cd := centerDistance1D(x) + centerDistance1D(y)
+			if !found || cd < d {
+				d = cd
+				found = true
+			}
+		}
+	}
+	return
+}
+
+func centerDistance1D(v uint8) int {
+	if v < 9 {
+		return int(9 - v)
+	}
+	if v > 10 {
+		return int(v - 10)
+	}
+	return 0
+}
+
+// EnemyContacts returns the number of edges between a cell of c and a cell of an enemy color
+func EnemyContacts(s blokus.State, c blokus.Color) (n int) {
+	enemies := blokus.EnemyColors(c)
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			if !blokus.HasColorAt(s, c, x, y) {
+				continue
+			}
+			for _, enemy := range enemies {
+				if blokus.HasColorAt(s, enemy, x-1, y) {
+					n++
+				}
+				if blokus.HasColorAt(s, enemy, x+1, y) {
+					n++
+				}
+				if blokus.HasColorAt(s, enemy, x, y-1) {
+					n++
+				}
+				if blokus.HasColorAt(s, enemy, x, y+1) {
+					n++
+				}
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,93 @@
+package eval
+
+import (
+	"github.com/hschendel/sc/2021/blokus"
+	"testing"
+)
+
+// testState has a blue TETRO_O in the upper left corner, and a yellow DOMINO right next to it. Yellow started with the
+// MONO in the upper right corner.
+func testState() *blokus.BasicState {
+	var s blokus.BasicState
+	s.Reset()
+	for _, cell := range []struct {
+		c    blokus.Color
+		x, y uint8
+	}{{blokus.ColorBlue, 0, 0}, {blokus.ColorBlue, 1, 0}, {blokus.ColorBlue, 0, 1}, {blokus.ColorBlue, 1, 1},
+		{blokus.ColorYellow, 2, 0}, {blokus.ColorYellow, 3, 0}, {blokus.ColorYellow, 19, 0}} {
+		s.Set(cell.x, cell.y, cell.c, true)
+	}
+	s.SetPiecePlayed(blokus.ColorBlue, blokus.PieceTetroO, true)
+	s.SetPiecePlayed(blokus.ColorYellow, blokus.PieceDomino, true)
+	s.SetPiecePlayed(blokus.ColorYellow, blokus.PieceMono, true)
+	return &s
+}
+
+func TestFeatures(t *testing.T) {
+	s := testState()
+	if n := Cells(s, blokus.ColorBlue); n != 4 {
+		t.Errorf("expected 4 blue cells but got %d", n)
+	}
+	box, found := BoundingBox(s, blokus.ColorBlue)
+	if !found || box != (Box{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1}) || box.Area() != 4 {
+		t.Errorf("expected 2x2 bounding box at 0,0 but got %+v, found %t", box, found)
+	}
+	box, found = BoundingBox(s, blokus.ColorYellow)
+	if !found || box.Width() != 18 || box.Height() != 1 || box.MinX != 2 {
+		t.Errorf("expected 18x1 bounding box at 2,0 but got %+v, found %t", box, found)
+	}
+	if _, found = BoundingBox(s, blokus.ColorGreen); found || BoundingBoxArea(s, blokus.ColorGreen) != 0 {
+		t.Errorf("expected no bounding box for green")
+	}
+	if n := AnchorCount(s, blokus.ColorBlue); n != 1 {
+		t.Errorf("expected 1 blue anchor but got %d", n)
+	}
+	// all free cells except the three free cells next to blue
+	if n := ReachableArea(s, blokus.ColorBlue); n != 400-7-3 {
+		t.Errorf("expected %d reachable cells for blue but got %d", 400-7-3, n)
+	}
+	if n := LegalMoves(s, blokus.ColorBlue); n != len(blokus.PossibleNextMoves(s, blokus.ColorBlue)) || n == 0 {
+		t.Errorf("unexpected number of legal blue moves %d", n)
+	}
+	if p, found := LargestFittingPiece(s, blokus.ColorBlue); !found || p.NumPoints() != 5 {
+		t.Errorf("expected a pentomino as largest fitting blue piece but got %s, found %t", p, found)
+	}
+	if d, found := CenterDistance(s, blokus.ColorBlue); !found || d != 16 {
+		t.Errorf("expected center distance 16 for blue but got %d, found %t", d, found)
+	}
+	if _, found := CenterDistance(s, blokus.ColorRed); found {
+		t.Errorf("expected no center distance for red")
+	}
+	if n := EnemyContacts(s, blokus.ColorBlue); n != 1 {
+		t.Errorf("expected 1 enemy contact for blue but got %d", n)
+	}
+	if n := EnemyContacts(s, blokus.ColorRed); n != 0 {
+		t.Errorf("expected no enemy contacts for red but got %d", n)
+	}
+}
+
+func TestPlayerDiff(t *testing.T) {
+	s := testState()
+	cells := func(s blokus.State, c blokus.Color) float64 { return float64(Cells(s, c)) }
+	if d := PlayerDiff(s, blokus.ColorRed, cells); d != 1 {
+		t.Errorf("expected cell difference 1 for the blue/red player but got %.0f", d)
+	}
+	if d := PlayerDiff(s, blokus.ColorGreen, cells); d != -1 {
+		t.Errorf("expected cell difference -1 for the yellow/green player but got %.0f", d)
+	}
+}
+
+func TestAll(t *testing.T) {
+	s := testState()
+	var empty blokus.BasicState
+	empty.Reset()
+	zeroWithoutPieces := map[string]bool{"cells": true, "bounding box area": true, "center distance": true, "enemy contacts": true}
+	for _, f := range All {
+		if v := f.Value(s, blokus.ColorBlue); v < 0 {
+			t.Errorf("expected feature %s not to be negative but got %f", f.Name, v)
+		}
+		if v := f.Value(&empty, blokus.ColorBlue); zeroWithoutPieces[f.Name] && v != 0 {
+			t.Errorf("expected feature %s to be 0 without pieces but got %f", f.Name, v)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package eval
+
+import (
+	"github.com/hschendel/sc/2021/blokus"
+)
+
+// Territory tells for every free cell which colors can still cover it. A color can reach a cell if the cell is
+// covered by one of its legal moves, or if it is connected to such a cell through free cells that are not next to a
+// cell of the color. So a color that is out of the game, e.g. because it skipped a move, without remaining pieces, or
+// without legal moves, cannot reach any cell.
+type Territory struct {
+	// reach has bit c set at [y][x] if color c can reach the cell x,y
+	reach [20][20]uint8
+	free  int
+}
+
+// AnalyzeTerritory calculates the Territory of all colors in s
+func AnalyzeTerritory(s blokus.State) (t *Territory) {
+	t = new(Territory)
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			if _, hasPiece := s.At(x, y); !hasPiece {
+				t.free++
+			}
+		}
+	}
+	for c := blokus.Color(0); c < 4; c++ {
+		t.fill(s, c)
+	}
+	return
+}
+
+func (t *Territory) fill(s blokus.State, c blokus.Color) {
+	if !s.IsColorValid(c) || len(s.NotPlayedPiecesFor(c)) == 0 {
+		return
+	}
+	bit := uint8(1) << c
+	var stack []blokus.Position
+	for _, m := range blokus.PossibleNextMoves(s, c) {
+		for _, p := range m.Transformation.Positions() {
+			x, y := m.X+p.X, m.Y+p.Y
+			if t.reach[y][x]&bit == 0 {
+				t.reach[y][x] |= bit
+				stack = append(stack, blokus.Position{X: x, Y: y})
+			}
+		}
+	}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, d := range [4][2]int8{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			x, y := uint8(int8(p.X)+d[0]), uint8(int8(p.Y)+d[1])
+			if x >= 20 || y >= 20 || t.reach[y][x]&bit != 0 || !canCover(s, c, x, y) {
+				continue
+			}
+			t.reach[y][x] |= bit
+			stack = append(stack, blokus.Position{X: x, Y: y})
+		}
+	}
+}
+
+// Reachable is true if c can still cover the cell x,y
+func (t *Territory) Reachable(c blokus.Color, x, y uint8) bool {
+	return t.reach[y][x]&(1<<c) != 0
+}
+
+// Reached returns the number of free cells c can reach
+func (t *Territory) Reached(c blokus.Color) int {
+	return t.count(func(reach uint8) bool { return reach&(1<<c) != 0 })
+}
+
+// Exclusive returns the number of free cells only c can reach
+func (t *Territory) Exclusive(c blokus.Color) int {
+	return t.count(func(reach uint8) bool { return reach == 1<<c })
+}
+
+// Contested returns the number of free cells that c and at least one other color can reach
+func (t *Territory) Contested(c blokus.Color) int {
+	return t.count(func(reach uint8) bool { return reach&(1<<c) != 0 && reach != 1<<c })
+}
+
+// Dead returns the number of free cells no color can reach
+func (t *Territory) Dead() int {
+	return t.free - t.count(func(reach uint8) bool { return reach != 0 })
+}
+
+func (t *Territory) count(f func(reach uint8) bool) (n int) {
+	for y := range t.reach {
+		for x := range t.reach[y] {
+			if f(t.reach[y][x]) {
+				n++
+			}
+		}
+	}
+	return
+}
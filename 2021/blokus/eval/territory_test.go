@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"github.com/hschendel/sc/2021/blokus"
+	"testing"
+)
+
+func TestAnalyzeTerritory(t *testing.T) {
+	s := testState()
+	// red and green are out of pieces, so only blue and yellow can reach cells
+	s.SetNotPlayedPiecesFor(blokus.ColorRed, nil)
+	s.SetNotPlayedPiecesFor(blokus.ColorGreen, nil)
+	tr := AnalyzeTerritory(s)
+
+	// 2,1 is next to both blue and yellow
+	if tr.Dead() != 1 || tr.Reachable(blokus.ColorBlue, 2, 1) || tr.Reachable(blokus.ColorYellow, 2, 1) {
+		t.Errorf("expected 2,1 to be the only dead cell, but got %d dead cells", tr.Dead())
+	}
+	// only yellow can reach 0,2 and 1,2 below blue, only blue can reach 4,0, 3,1, 18,0 and 19,1 next to yellow
+	for _, expected := range []struct {
+		c                           blokus.Color
+		reached, exclusive, contest int
+	}{{blokus.ColorBlue, 390, 4, 386}, {blokus.ColorYellow, 388, 2, 386}} {
+		if n := tr.Reached(expected.c); n != expected.reached {
+			t.Errorf("expected %s to reach %d cells but got %d", expected.c, expected.reached, n)
+		}
+		if n := tr.Exclusive(expected.c); n != expected.exclusive {
+			t.Errorf("expected %d exclusive cells for %s but got %d", expected.exclusive, expected.c, n)
+		}
+		if n := tr.Contested(expected.c); n != expected.contest {
+			t.Errorf("expected %d contested cells for %s but got %d", expected.contest, expected.c, n)
+		}
+	}
+	if !tr.Reachable(blokus.ColorYellow, 0, 2) || tr.Reachable(blokus.ColorBlue, 0, 2) {
+		t.Errorf("expected 0,2 to be exclusive to yellow")
+	}
+	if !tr.Reachable(blokus.ColorBlue, 4, 0) || tr.Reachable(blokus.ColorYellow, 4, 0) {
+		t.Errorf("expected 4,0 to be exclusive to blue")
+	}
+	for _, c := range []blokus.Color{blokus.ColorRed, blokus.ColorGreen} {
+		if n := tr.Reached(c); n != 0 {
+			t.Errorf("expected %s without pieces to reach no cells but got %d", c, n)
+		}
+	}
+}
+
+func TestAnalyzeTerritory_ColorOutOfGame(t *testing.T) {
+	s := testState()
+	s.SetNotPlayedPiecesFor(blokus.ColorRed, nil)
+	s.SetNotPlayedPiecesFor(blokus.ColorGreen, nil)
+	// yellow skipped, so it is out of the game although it still has legal moves
+	s.SetColorValid(blokus.ColorYellow, false)
+	if len(blokus.PossibleNextMoves(s, blokus.ColorYellow)) == 0 {
+		t.Fatalf("expected yellow to have legal moves")
+	}
+	tr := AnalyzeTerritory(s)
+	if n := tr.Reached(blokus.ColorYellow); n != 0 {
+		t.Errorf("expected yellow to reach no cells but got %d", n)
+	}
+	// blue reaches the same cells as before, but all of them exclusively, and 0,2 and 1,2 below blue are dead now
+	if n := tr.Exclusive(blokus.ColorBlue); n != 390 {
+		t.Errorf("expected 390 exclusive cells for blue but got %d", n)
+	}
+	if n := tr.Contested(blokus.ColorBlue); n != 0 {
+		t.Errorf("expected no contested cells for blue but got %d", n)
+	}
+	if n := tr.Dead(); n != 3 {
+		t.Errorf("expected 3 dead cells but got %d", n)
+	}
+}
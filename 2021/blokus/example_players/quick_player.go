@@ -3,12 +3,19 @@ package example_players
 import (
 	"github.com/hschendel/sc"
 	"github.com/hschendel/sc/2021/blokus"
+	"github.com/hschendel/sc/2021/blokus/eval"
 	"log"
 	"sort"
 	"time"
 )
 
-type QuickPlayer struct{}
+type QuickPlayer struct {
+	// LogBoard enables logging the board after each decision
+	LogBoard bool
+
+	lastEvaluation  []blokus.Evaluation
+	lastSearchStats blokus.SearchStats
+}
 
 func (q *QuickPlayer) NextMove(state blokus.State, color blokus.Color, timeout sc.Timeout) blokus.Move {
 	moves := blokus.PossibleNextMoves(state, color)
@@ -19,6 +26,16 @@ func (q *QuickPlayer) NextMove(state blokus.State, color blokus.Color, timeout s
 func (q *QuickPlayer) End() {
 }
 
+// LastEvaluation reports the rating of the last move picked, see blokus.EvaluationReporter
+func (q *QuickPlayer) LastEvaluation() []blokus.Evaluation {
+	return q.lastEvaluation
+}
+
+// LastSearchStats reports the number of moves rated for the last move, see blokus.SearchStatsReporter
+func (q *QuickPlayer) LastSearchStats() blokus.SearchStats {
+	return q.lastSearchStats
+}
+
 func (q *QuickPlayer) pickBestMove(s blokus.State, c blokus.Color, moves []blokus.Move) blokus.Move {
 	log.Printf("Pick move for %s", c.String())
 	var ms blokus.BasicState
@@ -50,6 +67,17 @@ func (q *QuickPlayer) pickBestMove(s blokus.State, c blokus.Color, moves []bloku
 	moveIdx := blokus.RandomInt(sameRatingIdx + 1)
 	move := ratedMoves[moveIdx].move
 	log.Printf("Picked move: %s\n  volume diff: %d\n  field coverage diff: %d\n", move.FormatPretty('X', "  "), ratedMoves[moveIdx].volumeDiff, ratedMoves[moveIdx].countDiff)
+	q.lastEvaluation = []blokus.Evaluation{
+		{Name: "volume diff", Value: float64(ratedMoves[moveIdx].volumeDiff)},
+		{Name: "field coverage diff", Value: float64(ratedMoves[moveIdx].countDiff)},
+		{Name: "possible moves", Value: float64(len(moves))},
+		{Name: "equally rated moves", Value: float64(sameRatingIdx + 1)},
+	}
+	q.lastSearchStats = blokus.SearchStats{Nodes: uint64(len(moves)), Depth: 1}
+	if q.LogBoard {
+		blokus.MustApplyMove(&ms, c, move)
+		log.Printf("Board after move:\n%s", blokus.FormatBoard(&ms, blokus.BoardFormat{LastMove: move}))
+	}
 	return move
 }
 
@@ -69,9 +97,7 @@ func (s sortRatedMovesQuick) Less(i, j int) bool {
 }
 
 func (s sortRatedMovesQuick) Swap(i, j int) {
-	tmp := s[i]
-	s[j] = s[i]
-	s[i] = tmp
+	s[i], s[j] = s[j], s[i]
 }
 
 func rateMoveQuick(s blokus.MutableState, c blokus.Color, m blokus.Move) (r ratedMoveQuick) {
@@ -95,39 +121,15 @@ type stateRatingQuick struct {
 }
 
 func rateStateQuick(s blokus.State, c blokus.Color) (rating stateRatingQuick) {
-	enemyColors := blokus.EnemyColors(c)
-	ownColors := blokus.OwnColors(c)
-	volume, count := countExtent(s)
-	rating.countDiff = count[ownColors[0]] + count[ownColors[1]] - count[enemyColors[0]] - count[enemyColors[1]]
-	rating.volumeDiff = volume[ownColors[0]] + volume[ownColors[1]] - volume[enemyColors[0]] - volume[enemyColors[1]]
+	rating.countDiff = int64(eval.PlayerDiff(s, c, cells))
+	rating.volumeDiff = int64(eval.PlayerDiff(s, c, boundingBoxArea))
 	return
 }
 
-func countExtent(s blokus.State) (v, n [4]int64) {
-	var minX, minY, maxX, maxY [4]uint8
-	for x := uint8(0); x < 20; x++ {
-		for y := uint8(0); y < 20; y++ {
-			c, hasPiece := s.At(x, y)
-			if !hasPiece {
-				continue
-			}
-			n[c] += 1
-			if x < minX[c] {
-				minX[c] = x
-			}
-			if y < minY[c] {
-				minY[c] = y
-			}
-			if x > maxX[c] {
-				maxX[c] = x
-			}
-			if y > maxY[c] {
-				maxY[c] = y
-			}
-		}
-	}
-	for c := uint8(0); c < 4; c++ {
-		v[c] = int64(maxX[c]-minX[c]) * int64(maxY[c]-minY[c])
-	}
-	return
+func cells(s blokus.State, c blokus.Color) float64 {
+	return float64(eval.Cells(s, c))
+}
+
+func boundingBoxArea(s blokus.State, c blokus.Color) float64 {
+	return float64(eval.BoundingBoxArea(s, c))
 }
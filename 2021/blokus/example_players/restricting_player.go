@@ -3,6 +3,8 @@ package example_players
 import (
 	"github.com/hschendel/sc"
 	"github.com/hschendel/sc/2021/blokus"
+	"github.com/hschendel/sc/2021/blokus/eval"
+	"github.com/hschendel/sc/2021/blokus/moveorder"
 	"log"
 	"sort"
 	"time"
@@ -13,7 +15,8 @@ type RestrictingPlayer struct{}
 
 func (rp *RestrictingPlayer) NextMove(state blokus.State, color blokus.Color, timeout sc.Timeout) blokus.Move {
 	moves := blokus.PossibleNextMoves(state, color)
-	presortMoves(state, color, moves)
+	// rate larger pieces reaching further first, in case the rating timeout is reached
+	moveorder.SortBySizeAndDirection(state, color, moves)
 	move := rp.pickBestMove(state, color, moves)
 	return move
 }
@@ -65,58 +68,6 @@ func (rp *RestrictingPlayer) pickBestMove(s blokus.State, c blokus.Color, moves
 	return move
 }
 
-func presortMoves(s blokus.State, c blokus.Color, moves []blokus.Move) {
-	var sm sortMovesRestricting
-	sm.m = moves
-	sm.goDown, sm.goRight = blokus.ColorDirection(s, c)
-	sort.Sort(&sm)
-}
-
-type sortMovesRestricting struct {
-	m       []blokus.Move
-	goDown  bool
-	goRight bool
-}
-
-func (s *sortMovesRestricting) Len() int {
-	return len(s.m)
-}
-
-func (s *sortMovesRestricting) Less(i, j int) bool {
-	if s.m[i].Transformation.Piece().NumPoints() != s.m[j].Transformation.Piece().NumPoints() {
-		return s.m[i].Transformation.Piece().NumPoints() > s.m[j].Transformation.Piece().NumPoints()
-	}
-	if s.goDown {
-		iy := s.m[i].Y + s.m[i].Transformation.Height() - 1
-		jy := s.m[j].Y + s.m[j].Transformation.Height() - 1
-		if iy != jy {
-			return iy > jy
-		}
-	} else {
-		if s.m[i].Y != s.m[j].Y {
-			return s.m[i].Y < s.m[j].Y
-		}
-	}
-	if s.goRight {
-		ix := s.m[i].X + s.m[i].Transformation.Width() - 1
-		jx := s.m[j].X + s.m[j].Transformation.Width() - 1
-		if ix != jx {
-			return ix > jx
-		}
-	} else {
-		if s.m[i].X != s.m[j].X {
-			return s.m[i].X < s.m[j].X
-		}
-	}
-	return false
-}
-
-func (s sortMovesRestricting) Swap(i, j int) {
-	tmp := s.m[i]
-	s.m[j] = s.m[i]
-	s.m[i] = tmp
-}
-
 type sortRatedMovesRestricting struct {
 	m []ratedMoveRestricting
 }
@@ -145,9 +96,7 @@ func (s *sortRatedMovesRestricting) Less(i, j int) bool {
 }
 
 func (s sortRatedMovesRestricting) Swap(i, j int) {
-	tmp := s.m[i]
-	s.m[j] = s.m[i]
-	s.m[i] = tmp
+	s.m[i], s.m[j] = s.m[j], s.m[i]
 }
 
 func (rp *RestrictingPlayer) rateMove(s blokus.MutableState, c blokus.Color, m blokus.Move) (r ratedMoveRestricting) {
@@ -167,7 +116,7 @@ type ratedMoveRestricting struct {
 
 type stateRatingRestricting struct {
 	enemyMoves uint64
-	volumeDiff uint64
+	volumeDiff int64
 	height     uint8
 	width      uint8
 }
@@ -178,41 +127,11 @@ func (r *stateRatingRestricting) area() uint64 {
 
 func rateState(s blokus.State, c blokus.Color) (rating stateRatingRestricting) {
 	enemyColors := blokus.EnemyColors(c)
-	ownColors := blokus.OwnColors(c)
-	rating.enemyMoves = uint64(len(blokus.PossibleNextMoves(s, enemyColors[0]))) + uint64(len(blokus.PossibleNextMoves(s, enemyColors[1])))
-	volume, height, width := volumeExtend(s)
-	rating.volumeDiff = volume[ownColors[0]] + volume[ownColors[1]] - volume[enemyColors[0]] - volume[enemyColors[1]]
-	rating.height = height[c]
-	rating.width = width[c]
-	return
-}
-
-func volumeExtend(s blokus.State) (v [4]uint64, h, w [4]uint8) {
-	var minX, minY, maxX, maxY [4]uint8
-	for x := uint8(0); x < 20; x++ {
-		for y := uint8(0); y < 20; y++ {
-			c, hasPiece := s.At(x, y)
-			if !hasPiece {
-				continue
-			}
-			if x < minX[c] {
-				minX[c] = x
-			}
-			if y < minY[c] {
-				minY[c] = y
-			}
-			if x > maxX[c] {
-				maxX[c] = x
-			}
-			if y > maxY[c] {
-				maxY[c] = y
-			}
-		}
-	}
-	for c := uint8(0); c < 4; c++ {
-		h[c] = maxY[c] - minY[c]
-		w[c] = maxX[c] - minX[c]
-		v[c] = uint64(w[c]) * uint64(h[c])
+	rating.enemyMoves = uint64(eval.LegalMoves(s, enemyColors[0]) + eval.LegalMoves(s, enemyColors[1]))
+	rating.volumeDiff = int64(eval.PlayerDiff(s, c, boundingBoxArea))
+	if box, found := eval.BoundingBox(s, c); found {
+		rating.height = box.Height()
+		rating.width = box.Width()
 	}
 	return
 }
@@ -1,18 +1,137 @@
 package blokus
 
 import (
+	"errors"
 	"fmt"
 	"github.com/hschendel/sc"
 	"io"
+	"math/rand"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"time"
 )
 
+// RunRepeatedGames plays repetitions games between player1 and player2 with the default timeouts, see MatchSeries
 func RunRepeatedGames(player1, player2 Player, player1Name, player2Name string, repetitions uint, logTo io.Writer) (result RepeatGameResult) {
-	for ri := uint(0); ri < repetitions; ri++ {
-		one, two := player1, player2
+	s := MatchSeries{
+		Player1:     player1,
+		Player2:     player2,
+		Player1Name: player1Name,
+		Player2Name: player2Name,
+		Repetitions: repetitions,
+		LogTo:       logTo,
+	}
+	return s.Run()
+}
+
+// MatchSeries plays a number of games between two players, with alternating player order
+type MatchSeries struct {
+	Player1     Player
+	Player2     Player
+	Player1Name string
+	Player2Name string
+	Repetitions uint
+	// Seed determines the seeds of all games if it is not 0, so that the series can be repeated with the same start
+	// pieces. Otherwise, every game gets a random seed.
+	Seed int64
+	// MoveTimeout and HardMoveTimeout are used for every game, see Match
+	MoveTimeout     time.Duration
+	HardMoveTimeout time.Duration
+	// LogTo receives the result of every game, if set
+	LogTo io.Writer
+	// OnGame is called after every game, if set
+	OnGame func(g *SeriesGame)
+}
+
+// SeriesGame is the outcome of a single game of a MatchSeries. Player 1 and 2 are the players of the series, not of
+// the game, so Result, the scores and the errors are swapped when Player1First is false.
+type SeriesGame struct {
+	// Number counts the games of the series, starting at 1
+	Number       uint
+	Player1Name  string
+	Player2Name  string
+	Seed         int64
+	StartPiece   Piece
+	Player1First bool
+	Result       GameResult
+	Score1       uint
+	Score2       uint
+	Error1       error
+	Error2       error
+	// Moves1 etc. contain the number of moves and the think times of the moves recorded for each player, and of the
+	// move that lost the game because of an error
+	Moves1        uint
+	Moves2        uint
+	ThinkTime1    time.Duration
+	ThinkTime2    time.Duration
+	MaxThinkTime1 time.Duration
+	MaxThinkTime2 time.Duration
+	// Record is the record of the game, with the colors as played, so Player1 is the second player in the record
+	// when Player1First is false
+	Record *GameRecord
+}
+
+// Run plays all games. A player that did not answer within the hard timeout loses all following games
+// until its NextMove call returns, on both seats if the same player plays both.
+func (s *MatchSeries) Run() (result RepeatGameResult) {
+	busy := make(map[Player]<-chan struct{})
+	nextSeed := RandomSeed
+	if s.Seed != 0 {
+		nextSeed = rand.New(rand.NewSource(s.Seed)).Int63
+	}
+	for ri := uint(0); ri < s.Repetitions; ri++ {
+		m := Match{
+			Player1:         s.Player1,
+			Player2:         s.Player2,
+			Seed:            nextSeed(),
+			MoveTimeout:     s.MoveTimeout,
+			HardMoveTimeout: s.HardMoveTimeout,
+			Record:          new(GameRecord),
+			busy:            busy,
+		}
 		if (ri % 2) == 1 {
-			one, two = player2, player1
+			m.Player1, m.Player2 = s.Player2, s.Player1
+		}
+		gameResult, score1, score2, err1, err2 := m.Run()
+		game := SeriesGame{
+			Number:       ri + 1,
+			Player1Name:  s.Player1Name,
+			Player2Name:  s.Player2Name,
+			Seed:         m.Seed,
+			StartPiece:   m.StartPiece(),
+			Player1First: ri%2 == 0,
+			Record:       m.Record,
+		}
+		timeout, _ := m.timeouts()
+		nearTimeout := time.Duration(float64(timeout) * NearTimeoutRatio)
+		moves := m.Record.Entries
+		// the move that lost the game because of an error is not recorded, but its think time counts, as it is
+		// usually the slowest one
+		for _, err := range []error{err1, err2} {
+			var gameErr *GameError
+			if errors.As(err, &gameErr) && gameErr.ThinkTime > 0 {
+				moves = append(moves[:len(moves):len(moves)], GameRecordEntry{Color: gameErr.Color, ThinkTime: gameErr.ThinkTime})
+			}
+		}
+		for i := range moves {
+			e := &moves[i]
+			if (uint(e.Color)+ri)%2 == 0 {
+				result.Player1MoveStats.add(e, nearTimeout)
+				game.Moves1++
+				game.ThinkTime1 += e.ThinkTime
+				if e.ThinkTime > game.MaxThinkTime1 {
+					game.MaxThinkTime1 = e.ThinkTime
+				}
+			} else {
+				result.Player2MoveStats.add(e, nearTimeout)
+				game.Moves2++
+				game.ThinkTime2 += e.ThinkTime
+				if e.ThinkTime > game.MaxThinkTime2 {
+					game.MaxThinkTime2 = e.ThinkTime
+				}
+			}
 		}
-		gameResult, score1, score2, err1, err2 := RunGame(one, two)
 		if (ri % 2) == 1 {
 			if gameResult == GameResultPlayer1Won {
 				gameResult = GameResultPlayer2Won
@@ -27,10 +146,10 @@ func RunRepeatedGames(player1, player2 Player, player1Name, player2Name string,
 		switch gameResult {
 		case GameResultPlayer1Won:
 			result.Player1Wins++
-			resultText = fmt.Sprintf("%s wins", player1Name)
+			resultText = fmt.Sprintf("%s wins", s.Player1Name)
 		case GameResultPlayer2Won:
 			result.Player2Wins++
-			resultText = fmt.Sprintf("%s wins", player2Name)
+			resultText = fmt.Sprintf("%s wins", s.Player2Name)
 		case GameResultDraw:
 			result.Draws++
 			resultText = "draw"
@@ -38,41 +157,77 @@ func RunRepeatedGames(player1, player2 Player, player1Name, player2Name string,
 
 		if err1 != nil {
 			result.Player1Errors = append(result.Player1Errors, err1)
+			countError(err1, &result.Player1Crashes, &result.Player1IllegalMoves, &result.Player1Timeouts)
 		}
 		if err2 != nil {
 			result.Player2Errors = append(result.Player2Errors, err2)
+			countError(err2, &result.Player2Crashes, &result.Player2IllegalMoves, &result.Player2Timeouts)
 		}
 
 		result.Player1TotalScore += score1
 		result.Player2TotalScore += score2
 
-		if logTo != nil {
-			fmt.Fprintf(logTo, "game %3d: %s, score %d:%d\n", ri+1, resultText, score1, score2)
+		if s.OnGame != nil {
+			game.Result = gameResult
+			game.Score1, game.Score2 = score1, score2
+			game.Error1, game.Error2 = err1, err2
+			s.OnGame(&game)
+		}
+
+		if s.LogTo != nil {
+			fmt.Fprintf(s.LogTo, "game %3d: %s, score %d:%d\n", ri+1, resultText, score1, score2)
 			if err1 != nil {
-				fmt.Fprintf(logTo, "  error reported from %s: %s\n", player1Name, err1)
+				fmt.Fprintf(s.LogTo, "  error reported from %s: %s\n", s.Player1Name, err1)
 			}
 			if err2 != nil {
-				fmt.Fprintf(logTo, "  error reported from %s: %s\n", player2Name, err2)
+				fmt.Fprintf(s.LogTo, "  error reported from %s: %s\n", s.Player2Name, err2)
 			}
 		}
 	}
 
-	result.Player1AvgScore = float64(result.Player1TotalScore) / float64(repetitions)
-	result.Player2AvgScore = float64(result.Player2TotalScore) / float64(repetitions)
+	result.Player1AvgScore = float64(result.Player1TotalScore) / float64(s.Repetitions)
+	result.Player2AvgScore = float64(result.Player2TotalScore) / float64(s.Repetitions)
+	result.Player1MoveStats.finish()
+	result.Player2MoveStats.finish()
 
 	return
 }
 
 type RepeatGameResult struct {
-	Draws             uint
-	Player1Wins       uint
-	Player2Wins       uint
-	Player1Errors     []error
-	Player2Errors     []error
-	Player1TotalScore uint
-	Player2TotalScore uint
-	Player1AvgScore   float64
-	Player2AvgScore   float64
+	Draws             uint    `json:"draws"`
+	Player1Wins       uint    `json:"player1Wins"`
+	Player2Wins       uint    `json:"player2Wins"`
+	Player1Errors     []error `json:"player1Errors,omitempty"`
+	Player2Errors     []error `json:"player2Errors,omitempty"`
+	Player1TotalScore uint    `json:"player1TotalScore"`
+	Player2TotalScore uint    `json:"player2TotalScore"`
+	Player1AvgScore   float64 `json:"player1AvgScore"`
+	Player2AvgScore   float64 `json:"player2AvgScore"`
+	// Player1Crashes etc. count the games lost because of the GameErrorKind of the error
+	Player1Crashes      uint `json:"player1Crashes"`
+	Player1IllegalMoves uint `json:"player1IllegalMoves"`
+	Player1Timeouts     uint `json:"player1Timeouts"`
+	Player2Crashes      uint `json:"player2Crashes"`
+	Player2IllegalMoves uint `json:"player2IllegalMoves"`
+	Player2Timeouts     uint `json:"player2Timeouts"`
+	// Player1MoveStats and Player2MoveStats summarize the think times and search stats of all moves
+	Player1MoveStats MoveStats `json:"player1MoveStats"`
+	Player2MoveStats MoveStats `json:"player2MoveStats"`
+}
+
+func countError(err error, crashes, illegalMoves, timeouts *uint) {
+	var gameErr *GameError
+	if !errors.As(err, &gameErr) {
+		return
+	}
+	switch gameErr.Kind {
+	case GameErrorPanic:
+		*crashes++
+	case GameErrorInvalidMove:
+		*illegalMoves++
+	case GameErrorTimeout:
+		*timeouts++
+	}
 }
 
 func (r *RepeatGameResult) Print(w io.Writer) {
@@ -83,6 +238,10 @@ func (r *RepeatGameResult) Print(w io.Writer) {
 	fmt.Fprintf(w, "Avg. score Player 1:  %5.1f\n\n", r.Player1AvgScore)
 	fmt.Fprintf(w, "Total score Player 2: %12d\n", r.Player2TotalScore)
 	fmt.Fprintf(w, "Avg. score Player 2:  %5.1f\n\n", r.Player2AvgScore)
+	fmt.Fprintf(w, "Crashes Player 1:     %3d, illegal moves: %3d, timeouts: %3d\n", r.Player1Crashes, r.Player1IllegalMoves, r.Player1Timeouts)
+	fmt.Fprintf(w, "Crashes Player 2:     %3d, illegal moves: %3d, timeouts: %3d\n\n", r.Player2Crashes, r.Player2IllegalMoves, r.Player2Timeouts)
+	r.Player1MoveStats.print(w, "Player 1")
+	r.Player2MoveStats.print(w, "Player 2")
 
 	printErrors(w, "Player 1", r.Player1Errors)
 	printErrors(w, "Player 2", r.Player2Errors)
@@ -93,6 +252,9 @@ func printErrors(w io.Writer, playerName string, errors []error) {
 		fmt.Fprintf(w, "\nReported errors for %s:\n", playerName)
 		for _, err := range errors {
 			fmt.Fprintf(w, "  %s\n", err)
+			if gameErr, isGameErr := err.(*GameError); isGameErr && gameErr.Stack != "" {
+				fmt.Fprintf(w, "%s\n", gameErr.Stack)
+			}
 		}
 	}
 }
@@ -105,109 +267,309 @@ const (
 	GameResultPlayer2Won
 )
 
+func (r GameResult) String() string {
+	switch r {
+	case GameResultDraw:
+		return "DRAW"
+	case GameResultPlayer1Won:
+		return "PLAYER1"
+	case GameResultPlayer2Won:
+		return "PLAYER2"
+	default:
+		panic(fmt.Sprintf("unknown GameResult value: %d", r))
+	}
+}
+
+func ParseGameResult(s string) (r GameResult, err error) {
+	switch strings.TrimSpace(s) {
+	case "DRAW":
+		r = GameResultDraw
+	case "PLAYER1":
+		r = GameResultPlayer1Won
+	case "PLAYER2":
+		r = GameResultPlayer2Won
+	default:
+		err = fmt.Errorf("unknown GameResult value: %q", s)
+	}
+	return
+}
+
+// RunGame runs a local game between player1 and player2 with a random start piece. See Match for more options.
 func RunGame(player1, player2 Player) (result GameResult, score1, score2 uint, err1, err2 error) {
-	const timeout = DefaultMoveTimeout
+	m := Match{
+		Player1: player1,
+		Player2: player2,
+		Seed:    RandomSeed(),
+	}
+	return m.Run()
+}
+
+// Match is a local game between two players
+type Match struct {
+	Player1     Player
+	Player2     Player
+	Player1Name string
+	Player2Name string
+	// Seed determines the start piece, so the same seed always leads to the same start piece
+	Seed int64
+	// Record is filled with the moves and result of the game, if set
+	Record *GameRecord
+	// OnMove is called after every move with all moves played so far, if set. When a player took back moves,
+	// entries is shorter than before. entries must not be modified.
+	OnMove func(entries []GameRecordEntry)
+	// MoveTimeout is the time a player has for a move, DefaultMoveTimeout if zero. A player answering later loses
+	// the game.
+	MoveTimeout time.Duration
+	// HardMoveTimeout is the time after which Match stops waiting for the move of a player, DefaultHardMoveTimeout
+	// if zero. The player loses the game, and its End method is called while NextMove may still be running.
+	HardMoveTimeout time.Duration
+	// Stop ends the game early when it is closed, if set. It is checked before every move, so a running NextMove
+	// call is not interrupted, and Run returns once it has answered. The game is then a draw, and both errors are
+	// ErrMatchStopped. Only a NextMove call abandoned after HardMoveTimeout can still be running when Run returns;
+	// its player is marked as busy, so the same Match or MatchSeries does not ask it again until it has returned.
+	Stop <-chan struct{}
+
+	// busy contains, for each player with a NextMove call abandoned after the hard timeout, a channel that is closed
+	// once that call returns. It is keyed by the player itself, so that a player playing both seats is not asked
+	// again while it is busy.
+	busy map[Player]<-chan struct{}
+}
+
+// DefaultHardMoveTimeout is the default for Match.HardMoveTimeout
+const DefaultHardMoveTimeout = 10 * time.Second
+
+// ErrMatchStopped is reported for both players when a Match was ended early by closing Match.Stop
+var ErrMatchStopped = errors.New("match was stopped")
+
+// StartPiece returns the start piece determined by m.Seed, one of StartPieces like on the official server
+func (m *Match) StartPiece() Piece {
+	rnd := rand.New(rand.NewSource(m.Seed))
+	return StartPieces[rnd.Intn(len(StartPieces))]
+}
+
+// Run plays the game until it has ended, or until a player made an invalid move, panicked or hit the timeout.
+// In the latter cases, the other player wins, and the error is reported in err1 or err2.
+// Players implementing InteractivePlayer are not subject to the timeout, and may take back their moves.
+func (m *Match) Run() (result GameResult, score1, score2 uint, err1, err2 error) {
+	timeout, hardTimeout := m.timeouts()
+	record := GameRecord{
+		StartPiece:  m.StartPiece(),
+		Player1Name: m.Player1Name,
+		Player2Name: m.Player2Name,
+		Seed:        m.Seed,
+	}
+	if m.Record != nil {
+		defer m.finishRecord(&record, &result, &score1, &score2, &err1, &err2)
+	}
 	var state, copyState BasicState
-	state.SetStartPiece(AllPieces[RandomInt(len(AllPieces))])
-	var tr turnTracker
-	tr.players[0] = player1
-	tr.players[1] = player2
-
-	for ; !tr.gameEnded; tr.nextColor() {
-		player, color := tr.current()
-		if !tr.firstRound() && !HasPossibleNextMoves(&state, color) {
-			tr.endCurrent()
-			continue
+	game := NewGame(&state, record.StartPiece)
+	players := [2]Player{m.Player1, m.Player2}
+
+	for !game.IsOver() {
+		if m.stopped() {
+			score1, score2 = game.Scores()
+			err1, err2 = ErrMatchStopped, ErrMatchStopped
+			return
 		}
+		color := game.CurrentColor()
+		player := players[color%2]
+
 		CopyState(&copyState, &state)
-		t := sc.NewTimeout(timeout)
-		move := player.NextMove(&copyState, color, sc.NewTimeout(timeout))
-		timeoutReached := t.Reached()
+		interactivePlayer, isInteractive := player.(InteractivePlayer)
+		var move Move
+		var thinkTime time.Duration
+		var answerErr *GameError
+		if isInteractive {
+			startT := time.Now()
+			move, answerErr = callNextMove(player, &copyState, color, sc.NewTimeout(timeout))
+			thinkTime = time.Since(startT)
+		} else {
+			move, thinkTime, answerErr = m.nextMove(player, &copyState, color, timeout, hardTimeout)
+		}
+		if answerErr != nil {
+			answerErr.ThinkTime = thinkTime
+			score1, score2 = game.Scores()
+			result, err1, err2 = setErrorResult(answerErr)
+			return
+		}
+		timeoutReached := thinkTime >= timeout
 
-		if move.IsEmpty() {
-			if tr.firstRound() {
-				result, err1, err2 = setErrorResult(color, "first move must not be empty")
-				return
+		if isInteractive {
+			if interactivePlayer.UndoRequested() {
+				if i := lastEntryOfPlayer(record.Entries, color); i >= 0 {
+					for len(record.Entries) > i {
+						game.Undo()
+						record.Entries = record.Entries[:len(record.Entries)-1]
+					}
+				}
+				if m.OnMove != nil {
+					m.OnMove(record.Entries)
+				}
+				continue
 			}
-		} else {
-			if state.IsPiecePlayed(color, move.Transformation.Piece()) {
-				result, err1, err2 = setErrorResult(color, "invalid move: piece %s was already played", move.Transformation.Piece().String())
-				return
+			timeoutReached = false
+		}
+
+		var gameErr *GameError
+		if move.IsEmpty() {
+			if !state.HasPlayed(color) {
+				gameErr = newGameError(color, GameErrorInvalidMove, "first move must not be empty")
 			}
-			if !CanPlayNextPiece(&state, color, move.Transformation, move.X, move.Y) {
-				result, err1, err2 = setErrorResult(color, "invalid move: %s", move.FormatPretty('X', "  "))
-				return
+		} else if state.IsPiecePlayed(color, move.Transformation.Piece()) {
+			gameErr = newGameError(color, GameErrorInvalidMove, "invalid move: piece %s was already played", move.Transformation.Piece().String())
+		} else if !CanPlayNextPiece(&state, color, move.Transformation, move.X, move.Y) {
+			gameErr = newGameError(color, GameErrorInvalidMove, "invalid move: %s", move.FormatPretty('X', "  "))
+		}
+		if gameErr == nil && timeoutReached {
+			gameErr = newGameError(color, GameErrorTimeout, "player hit timeout")
+		}
+		if gameErr == nil {
+			if moveErr := game.Play(move); moveErr != nil {
+				gameErr = newGameError(color, GameErrorInvalidMove, "move error: %s", moveErr)
 			}
 		}
-
-		if timeoutReached {
-			result, err1, err2 = setErrorResult(color, "player hit timeout")
+		if gameErr != nil {
+			gameErr.ThinkTime = thinkTime
+			score1, score2 = game.Scores()
+			result, err1, err2 = setErrorResult(gameErr)
 			return
 		}
-
-		if !move.IsEmpty() {
-			moveErr := ApplyMove(&state, color, move)
-			if moveErr != nil {
-				result, err1, err2 = setErrorResult(color, "move error: %s", moveErr)
-				return
-			}
-			score1, score2 = updateScore(color, move, score1, score2)
+		entry := GameRecordEntry{
+			Color:     color,
+			Move:      move,
+			ThinkTime: thinkTime,
+		}
+		if reporter, isReporter := player.(EvaluationReporter); isReporter {
+			entry.Evaluation = reporter.LastEvaluation()
+		}
+		if reporter, isReporter := player.(SearchStatsReporter); isReporter {
+			stats := reporter.LastSearchStats()
+			entry.SearchStats = &stats
+		}
+		record.Entries = append(record.Entries, entry)
+		if m.OnMove != nil {
+			m.OnMove(record.Entries)
 		}
 	}
-	result, score1, score2 = finalizeScore(score1, score2, &state)
+	score1, score2 = game.Scores()
+	result = game.Winner()
 	return
 }
 
-type turnTracker struct {
-	color           Color
-	players         [2]Player
-	colorEnded      [4]bool
-	roundsCompleted uint
-	gameEnded       bool
+// stopped returns true if m.Stop is closed
+func (m *Match) stopped() bool {
+	select {
+	case <-m.Stop:
+		return true
+	default:
+		return false
+	}
 }
 
-func (t *turnTracker) current() (p Player, c Color) {
-	p = t.players[t.color%2]
-	c = t.color
-	return
+// lastEntryOfPlayer returns the index of the last entry played by the player owning color c, or -1
+func lastEntryOfPlayer(entries []GameRecordEntry, c Color) int {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Color%2 == c%2 {
+			return i
+		}
+	}
+	return -1
 }
 
-func (t *turnTracker) endCurrent() {
-	t.colorEnded[t.color] = true
-	oldColor := t.color
-	t.color = (t.color + 2) % 4
-	if t.colorEnded[t.color] {
-		t.color = oldColor
-		t.gameEnded = true
+func (m *Match) finishRecord(record *GameRecord, result *GameResult, score1, score2 *uint, err1, err2 *error) {
+	record.Result = *result
+	record.Score1 = *score1
+	record.Score2 = *score2
+	if *err1 != nil {
+		record.Error1 = (*err1).Error()
+	}
+	if *err2 != nil {
+		record.Error2 = (*err2).Error()
+	}
+	*m.Record = *record
+}
+
+// timeouts returns the soft and hard move timeout of m, using the defaults for unset values
+func (m *Match) timeouts() (timeout, hardTimeout time.Duration) {
+	timeout, hardTimeout = m.MoveTimeout, m.HardMoveTimeout
+	if timeout == 0 {
+		timeout = DefaultMoveTimeout
+	}
+	if hardTimeout == 0 {
+		hardTimeout = DefaultHardMoveTimeout
+	}
+	if hardTimeout < timeout {
+		hardTimeout = timeout
 	}
 	return
 }
 
-func (t *turnTracker) nextColor() {
-	oldColor := t.color
-	t.color = (t.color + 1) % 4
-	if t.colorEnded[t.color] {
-		t.color = (t.color + 2) % 4
-		t.gameEnded = t.colorEnded[t.color]
-		if t.gameEnded {
-			t.color = oldColor
+type nextMoveAnswer struct {
+	move     Move
+	panicErr *GameError
+}
+
+// nextMove asks p for its next move in a separate goroutine, and gives up when p did not answer within
+// hardTimeout. In that case End is called on p, and p is marked as busy until its NextMove returns, so that it is
+// not asked again while it is still running. Players whose type is not comparable cannot be told apart, so they
+// are not marked.
+func (m *Match) nextMove(p Player, s State, c Color, timeout, hardTimeout time.Duration) (move Move, thinkTime time.Duration, gameErr *GameError) {
+	trackable := reflect.TypeOf(p).Comparable()
+	if trackable {
+		if busy, found := m.busy[p]; found {
+			select {
+			case <-busy:
+				delete(m.busy, p)
+			default:
+				gameErr = newGameError(c, GameErrorTimeout, "player is still busy with a move of a previous game")
+				return
+			}
 		}
 	}
-	if oldColor == 3 && !t.gameEnded {
-		t.roundsCompleted++
+	answer := make(chan nextMoveAnswer, 1)
+	done := make(chan struct{})
+	startT := time.Now()
+	go func() {
+		defer close(done)
+		var a nextMoveAnswer
+		a.move, a.panicErr = callNextMove(p, s, c, sc.NewTimeout(timeout))
+		answer <- a
+	}()
+	hardT := time.NewTimer(hardTimeout)
+	defer hardT.Stop()
+	select {
+	case a := <-answer:
+		thinkTime = time.Since(startT)
+		move, gameErr = a.move, a.panicErr
+	case <-hardT.C:
+		thinkTime = time.Since(startT)
+		if trackable {
+			if m.busy == nil {
+				m.busy = make(map[Player]<-chan struct{})
+			}
+			m.busy[p] = done
+		}
+		p.End()
+		gameErr = newGameError(c, GameErrorTimeout, "player did not answer within the hard timeout of %s", hardTimeout)
 	}
 	return
 }
 
-func (t *turnTracker) firstRound() bool {
-	return t.roundsCompleted == 0
+// callNextMove asks p for its next move, and turns a panic of p into a GameError
+func callNextMove(p Player, s State, c Color, timeout sc.Timeout) (m Move, panicErr *GameError) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr = newGameError(c, GameErrorPanic, "panic: %v", r)
+			panicErr.Stack = string(debug.Stack())
+		}
+	}()
+	m = p.NextMove(s, c, timeout)
+	return
 }
 
-func setErrorResult(c Color, format string, params ...interface{}) (result GameResult, err1, err2 error) {
-	playerIdx := uint8(c) % 2
-	msg := fmt.Sprintf(format, params...)
-	err := fmt.Errorf("%s: %s", c.String(), msg)
-	if playerIdx == 0 {
+// setErrorResult lets the opponent of the player that caused err win
+func setErrorResult(err *GameError) (result GameResult, err1, err2 error) {
+	if err.Color%2 == 0 {
 		result = GameResultPlayer2Won
 		err1 = err
 	} else {
@@ -217,40 +579,146 @@ func setErrorResult(c Color, format string, params ...interface{}) (result GameR
 	return
 }
 
-func updateScore(c Color, m Move, score1, score2 uint) (newScore1, newScore2 uint) {
-	newScore1, newScore2 = score1, score2
-	playerIdx := uint8(c) % 2
-	points := m.Transformation.Piece().NumPoints()
-	if playerIdx == 0 {
-		newScore1 += points
-	} else {
-		newScore2 += points
+// ErrGameOver is returned by Game.Play when the game has already ended
+var ErrGameOver = errors.New("game is over")
+
+// Game applies moves to a MutableState following the rules of the official server: the colors move in the order
+// blue, yellow, red, green; every color starts with the start piece in a free corner; skipping is not allowed in the
+// first move of a color, and a color that skips is out of the game, just like a color that cannot place any piece
+// anymore. The game is over when no color is left.
+// The state's CurrentColor() and IsColorValid() are kept up to date, so it can be passed to players directly.
+type Game struct {
+	state   MutableState
+	history []gameHistoryEntry
+}
+
+// gameHistoryEntry contains everything needed to undo a move
+type gameHistoryEntry struct {
+	color        Color
+	move         Move
+	valid        [4]bool
+	lastMoveMono bool
+}
+
+// NewGame resets s and starts a new game on it with the given start piece
+func NewGame(s MutableState, startPiece Piece) *Game {
+	s.Reset()
+	s.SetStartPiece(startPiece)
+	for c := Color(0); c < 4; c++ {
+		s.SetColorValid(c, true)
 	}
-	return
+	s.SetCurrentColor(ColorBlue)
+	return &Game{state: s}
 }
 
-func finalizeScore(score1, score2 uint, s State) (result GameResult, finalScore1, finalScore2 uint) {
-	finalScore1, finalScore2 = score1, score2
+// ResumeGame continues the game in s, e.g. a state received from the server. Moves played before cannot be undone.
+func ResumeGame(s MutableState) *Game {
+	g := &Game{state: s}
+	if c := s.CurrentColor(); !g.canMove(c) {
+		s.SetColorValid(c, false)
+		g.advance(c)
+	}
+	return g
+}
+
+// State returns the state the game is played on. It must not be modified directly.
+func (g *Game) State() State {
+	return g.state
+}
+
+// CurrentColor is the color to move
+func (g *Game) CurrentColor() Color {
+	return g.state.CurrentColor()
+}
+
+// IsOver is true if no color can move anymore
+func (g *Game) IsOver() bool {
+	return HasGameEnded(g.state)
+}
+
+// Turn returns the number of moves played since the game was started or resumed
+func (g *Game) Turn() int {
+	return len(g.history)
+}
+
+// LegalMoves returns the pieces the current color can place. Skipping is legal as well after the first move of
+// the color, but ends the color, so it is not included.
+func (g *Game) LegalMoves() []Move {
+	if g.IsOver() {
+		return nil
+	}
+	return PossibleNextMoves(g.state, g.CurrentColor())
+}
+
+// Play plays m for the current color. An empty move skips, which ends the color.
+func (g *Game) Play(m Move) error {
+	if g.IsOver() {
+		return ErrGameOver
+	}
+	c := g.CurrentColor()
+	h := gameHistoryEntry{color: c, move: m, lastMoveMono: g.state.IsLastMoveMono(c)}
+	for vc := Color(0); vc < 4; vc++ {
+		h.valid[vc] = g.state.IsColorValid(vc)
+	}
+	if m.IsEmpty() {
+		if !g.state.HasPlayed(c) {
+			return ErrForbiddenMove
+		}
+		g.state.SetColorValid(c, false)
+	} else if err := ApplyMove(g.state, c, m); err != nil {
+		return err
+	}
+	g.history = append(g.history, h)
+	g.advance(c)
+	return nil
+}
+
+// Undo takes back the last move played. It returns false if there is no move to take back.
+func (g *Game) Undo() bool {
+	if len(g.history) == 0 {
+		return false
+	}
+	h := g.history[len(g.history)-1]
+	g.history = g.history[:len(g.history)-1]
+	if !h.move.IsEmpty() {
+		UndoMove(g.state, h.color, h.move)
+	}
+	g.state.SetLastMoveMono(h.color, h.lastMoveMono)
 	for c := Color(0); c < 4; c++ {
-		addScore := uint(0)
-		if len(s.NotPlayedPiecesFor(c)) == 0 {
-			addScore += 15
-			if s.IsLastMoveMono(c) {
-				addScore += 5
-			}
+		g.state.SetColorValid(c, h.valid[c])
+	}
+	g.state.SetCurrentColor(h.color)
+	return true
+}
+
+// advance makes the next color after c the current one that is still valid, and ends all colors on the way that
+// cannot move anymore
+func (g *Game) advance(c Color) {
+	for i := 0; i < 4; i++ {
+		c = (c + 1) % 4
+		if !g.state.IsColorValid(c) {
+			continue
 		}
-		if (c % 2) == 0 {
-			finalScore1 += addScore
-		} else {
-			finalScore2 += addScore
+		if g.canMove(c) {
+			g.state.SetCurrentColor(c)
+			return
 		}
+		g.state.SetColorValid(c, false)
 	}
-	if finalScore1 > finalScore2 {
-		result = GameResultPlayer1Won
-	} else if finalScore2 > finalScore1 {
-		result = GameResultPlayer2Won
-	} else {
-		result = GameResultDraw
-	}
+}
+
+func (g *Game) canMove(c Color) bool {
+	return g.state.IsColorValid(c) && (!g.state.HasPlayed(c) || HasPossibleNextMoves(g.state, c))
+}
+
+// Scores returns the points of both players, see OfficialResult
+func (g *Game) Scores() (score1, score2 uint) {
+	_, score1, score2 = OfficialResult(g.state)
+	return
+}
+
+// Winner returns the result according to Scores. It can be called before the game is over, to get the current lead.
+func (g *Game) Winner() (result GameResult) {
+	result, _, _ = OfficialResult(g.state)
 	return
 }
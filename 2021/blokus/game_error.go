@@ -0,0 +1,71 @@
+package blokus
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// GameErrorKind tells why a player lost a game because of an error
+type GameErrorKind uint8
+
+const (
+	GameErrorInvalidMove = GameErrorKind(iota)
+	GameErrorTimeout
+	GameErrorPanic
+)
+
+func (k GameErrorKind) String() string {
+	switch k {
+	case GameErrorInvalidMove:
+		return "INVALID_MOVE"
+	case GameErrorTimeout:
+		return "TIMEOUT"
+	case GameErrorPanic:
+		return "PANIC"
+	default:
+		panic(fmt.Sprintf("unknown GameErrorKind value: %d", k))
+	}
+}
+
+// GameError is the error reported by Match.Run for a player that lost the game because of an invalid move, a timeout
+// or a panic
+type GameError struct {
+	Color   Color
+	Kind    GameErrorKind
+	Message string
+	// Stack is the stack trace of the panic, if Kind is GameErrorPanic
+	Stack string
+	// ThinkTime is the time the player took for the move that caused the error, or until Match gave up waiting for
+	// it. It is 0 if the player was not asked for a move, e.g. because it was still busy.
+	ThinkTime time.Duration
+}
+
+func newGameError(c Color, kind GameErrorKind, format string, params ...interface{}) *GameError {
+	return &GameError{Color: c, Kind: kind, Message: fmt.Sprintf(format, params...)}
+}
+
+func (e *GameError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Color.String(), e.Message)
+}
+
+func (e *GameError) MarshalJSON() ([]byte, error) {
+	je := jsonGameError{
+		Color:   e.Color.String(),
+		Kind:    e.Kind.String(),
+		Message: e.Message,
+		Stack:   e.Stack,
+	}
+	if e.ThinkTime != 0 {
+		je.ThinkTime = e.ThinkTime.String()
+	}
+	return json.Marshal(&je)
+}
+
+type jsonGameError struct {
+	Color     string `json:"color"`
+	Kind      string `json:"kind"`
+	Message   string `json:"message"`
+	Stack     string `json:"stack,omitempty"`
+	ThinkTime string `json:"thinkTime,omitempty"`
+}
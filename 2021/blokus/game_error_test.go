@@ -0,0 +1,154 @@
+package blokus
+
+import (
+	"errors"
+	"github.com/hschendel/sc"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// panickingPlayer plays the first possible move, but panics on its second move
+type panickingPlayer struct {
+	moves int
+}
+
+func (p *panickingPlayer) NextMove(state State, color Color, timeout sc.Timeout) Move {
+	p.moves++
+	if p.moves == 2 {
+		var m map[string]int
+		m["boom"]++
+	}
+	return firstMovePlayer{}.NextMove(state, color, timeout)
+}
+
+func (p *panickingPlayer) End() {
+}
+
+func TestMatchRecoversPanic(t *testing.T) {
+	var record GameRecord
+	m := Match{Player1: firstMovePlayer{}, Player2: &panickingPlayer{}, Seed: 1, Record: &record}
+	result, score1, score2, err1, err2 := m.Run()
+	if result != GameResultPlayer1Won {
+		t.Errorf("expected player 1 to win but got %s", result)
+	}
+	if err1 != nil {
+		t.Errorf("unexpected error for player 1: %s", err1)
+	}
+	var gameErr *GameError
+	if !errors.As(err2, &gameErr) {
+		t.Fatalf("expected a GameError for player 2 but got %v", err2)
+	}
+	if gameErr.Kind != GameErrorPanic || gameErr.Color != ColorGreen {
+		t.Errorf("expected a panic of GREEN but got %s of %s", gameErr.Kind, gameErr.Color)
+	}
+	if !strings.Contains(gameErr.Stack, "panickingPlayer") {
+		t.Errorf("expected stack trace to contain the panicking player, but got:\n%s", gameErr.Stack)
+	}
+	if score1 == 0 || score2 == 0 {
+		t.Errorf("expected the scores of the moves played before the panic but got %d:%d", score1, score2)
+	}
+	if record.Error2 != err2.Error() {
+		t.Errorf("expected recorded error %q but got %q", err2.Error(), record.Error2)
+	}
+}
+
+func TestRunRepeatedGamesCountsErrors(t *testing.T) {
+	result := RunRepeatedGames(&panickingPlayer{}, firstMovePlayer{}, "panicking", "first", 1, nil)
+	if result.Player2Wins != 1 {
+		t.Errorf("expected 1 win for player 2 but got %d", result.Player2Wins)
+	}
+	if result.Player1Crashes != 1 || result.Player1IllegalMoves != 0 || result.Player1Timeouts != 0 {
+		t.Errorf("expected 1 crash of player 1 but got %d crashes, %d illegal moves, %d timeouts",
+			result.Player1Crashes, result.Player1IllegalMoves, result.Player1Timeouts)
+	}
+	if result.Player2Crashes != 0 || len(result.Player2Errors) != 0 {
+		t.Errorf("expected no errors of player 2 but got %v", result.Player2Errors)
+	}
+}
+
+// hangingPlayer plays the first possible move, but does not answer its second move until release is closed
+type hangingPlayer struct {
+	release chan struct{}
+	moves   int32
+	ended   chan struct{}
+}
+
+func newHangingPlayer() *hangingPlayer {
+	return &hangingPlayer{release: make(chan struct{}), ended: make(chan struct{}, 10)}
+}
+
+func (h *hangingPlayer) NextMove(state State, color Color, timeout sc.Timeout) Move {
+	if atomic.AddInt32(&h.moves, 1) == 2 {
+		<-h.release
+	}
+	return firstMovePlayer{}.NextMove(state, color, timeout)
+}
+
+func (h *hangingPlayer) End() {
+	h.ended <- struct{}{}
+}
+
+func TestMatchHardTimeout(t *testing.T) {
+	h := newHangingPlayer()
+	defer close(h.release)
+	m := Match{Player1: h, Player2: firstMovePlayer{}, Seed: 1, MoveTimeout: 10 * time.Millisecond, HardMoveTimeout: 50 * time.Millisecond}
+	result, _, _, err1, _ := m.Run()
+	if result != GameResultPlayer2Won {
+		t.Errorf("expected player 2 to win but got %s", result)
+	}
+	var gameErr *GameError
+	if !errors.As(err1, &gameErr) || gameErr.Kind != GameErrorTimeout {
+		t.Fatalf("expected a timeout of player 1 but got %v", err1)
+	}
+	if gameErr.ThinkTime < 50*time.Millisecond {
+		t.Errorf("expected the think time until the hard timeout, but got %s", gameErr.ThinkTime)
+	}
+	select {
+	case <-h.ended:
+	default:
+		t.Errorf("expected End to be called on the player that hit the hard timeout")
+	}
+}
+
+func TestMatchSeriesBusyPlayer(t *testing.T) {
+	h := newHangingPlayer()
+	defer close(h.release)
+	s := MatchSeries{
+		Player1:         h,
+		Player2:         firstMovePlayer{},
+		Repetitions:     3,
+		MoveTimeout:     10 * time.Millisecond,
+		HardMoveTimeout: 50 * time.Millisecond,
+	}
+	result := s.Run()
+	if result.Player2Wins != 3 || result.Player1Timeouts != 3 {
+		t.Errorf("expected 3 wins of player 2 by timeout but got %d wins and %d timeouts", result.Player2Wins, result.Player1Timeouts)
+	}
+	if moves := atomic.LoadInt32(&h.moves); moves != 2 {
+		t.Errorf("expected the hanging player not to be asked again while busy, but it was asked for %d moves", moves)
+	}
+}
+
+func TestMatchSeriesBusyPlayerOnBothSeats(t *testing.T) {
+	h := newHangingPlayer()
+	defer close(h.release)
+	// hang in the first move, so that the player hangs as player 1 of the series, and is asked as player 2 first in
+	// the next game
+	h.moves = 1
+	s := MatchSeries{
+		Player1:         h,
+		Player2:         h,
+		Repetitions:     2,
+		MoveTimeout:     10 * time.Millisecond,
+		HardMoveTimeout: 50 * time.Millisecond,
+	}
+	result := s.Run()
+	if result.Player1Timeouts+result.Player2Timeouts != 2 {
+		t.Errorf("expected both games to end by timeout, but got %d and %d timeouts", result.Player1Timeouts, result.Player2Timeouts)
+	}
+	if moves := atomic.LoadInt32(&h.moves); moves != 2 {
+		t.Errorf("expected the hanging player not to be asked again on the other seat while busy, but it was asked for %d moves", moves)
+	}
+}
@@ -0,0 +1,124 @@
+package blokus
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// GameLog writes one line per game of a MatchSeries, see MatchSeries.OnGame
+type GameLog interface {
+	WriteGame(g *SeriesGame) error
+}
+
+// NewCSVGameLog returns a GameLog writing CSV with a header line to w
+func NewCSVGameLog(w io.Writer) GameLog {
+	return &csvGameLog{w: csv.NewWriter(w)}
+}
+
+// NewJSONLGameLog returns a GameLog writing one JSON object per line to w
+func NewJSONLGameLog(w io.Writer) GameLog {
+	return &jsonlGameLog{enc: json.NewEncoder(w)}
+}
+
+var csvGameLogHeader = []string{
+	"game", "player1", "player2", "seed", "startPiece", "player1First", "result", "score1", "score2", "error1",
+	"error2", "moves1", "moves2", "thinkTime1Ns", "thinkTime2Ns", "maxThinkTime1Ns", "maxThinkTime2Ns",
+}
+
+type csvGameLog struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (l *csvGameLog) WriteGame(g *SeriesGame) error {
+	if !l.headerWritten {
+		if err := l.w.Write(csvGameLogHeader); err != nil {
+			return err
+		}
+		l.headerWritten = true
+	}
+	jg := newJSONSeriesGame(g)
+	record := []string{
+		strconv.FormatUint(uint64(jg.Number), 10),
+		jg.Player1Name,
+		jg.Player2Name,
+		strconv.FormatInt(jg.Seed, 10),
+		jg.StartPiece,
+		strconv.FormatBool(jg.Player1First),
+		jg.Result,
+		strconv.FormatUint(uint64(jg.Score1), 10),
+		strconv.FormatUint(uint64(jg.Score2), 10),
+		errorMessage(g.Error1),
+		errorMessage(g.Error2),
+		strconv.FormatUint(uint64(jg.Moves1), 10),
+		strconv.FormatUint(uint64(jg.Moves2), 10),
+		strconv.FormatInt(jg.ThinkTime1, 10),
+		strconv.FormatInt(jg.ThinkTime2, 10),
+		strconv.FormatInt(jg.MaxThinkTime1, 10),
+		strconv.FormatInt(jg.MaxThinkTime2, 10),
+	}
+	if err := l.w.Write(record); err != nil {
+		return err
+	}
+	l.w.Flush()
+	return l.w.Error()
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+type jsonlGameLog struct {
+	enc *json.Encoder
+}
+
+func (l *jsonlGameLog) WriteGame(g *SeriesGame) error {
+	return l.enc.Encode(newJSONSeriesGame(g))
+}
+
+type jsonSeriesGame struct {
+	Number        uint   `json:"game"`
+	Player1Name   string `json:"player1"`
+	Player2Name   string `json:"player2"`
+	Seed          int64  `json:"seed"`
+	StartPiece    string `json:"startPiece"`
+	Player1First  bool   `json:"player1First"`
+	Result        string `json:"result"`
+	Score1        uint   `json:"score1"`
+	Score2        uint   `json:"score2"`
+	Error1        error  `json:"error1,omitempty"`
+	Error2        error  `json:"error2,omitempty"`
+	Moves1        uint   `json:"moves1"`
+	Moves2        uint   `json:"moves2"`
+	ThinkTime1    int64  `json:"thinkTime1Ns"`
+	ThinkTime2    int64  `json:"thinkTime2Ns"`
+	MaxThinkTime1 int64  `json:"maxThinkTime1Ns"`
+	MaxThinkTime2 int64  `json:"maxThinkTime2Ns"`
+}
+
+func newJSONSeriesGame(g *SeriesGame) *jsonSeriesGame {
+	return &jsonSeriesGame{
+		Number:        g.Number,
+		Player1Name:   g.Player1Name,
+		Player2Name:   g.Player2Name,
+		Seed:          g.Seed,
+		StartPiece:    g.StartPiece.String(),
+		Player1First:  g.Player1First,
+		Result:        g.Result.String(),
+		Score1:        g.Score1,
+		Score2:        g.Score2,
+		Error1:        g.Error1,
+		Error2:        g.Error2,
+		Moves1:        g.Moves1,
+		Moves2:        g.Moves2,
+		ThinkTime1:    int64(g.ThinkTime1),
+		ThinkTime2:    int64(g.ThinkTime2),
+		MaxThinkTime1: int64(g.MaxThinkTime1),
+		MaxThinkTime2: int64(g.MaxThinkTime2),
+	}
+}
@@ -0,0 +1,64 @@
+package blokus
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestGameLogs(t *testing.T) {
+	g := SeriesGame{
+		Number:       2,
+		Player1Name:  "one",
+		Player2Name:  "two",
+		Seed:         42,
+		StartPiece:   PiecePentoL,
+		Player1First: false,
+		Result:       GameResultPlayer1Won,
+		Score1:       10,
+		Score2:       5,
+		Error2:       newGameError(ColorBlue, GameErrorTimeout, "player hit timeout"),
+		Moves1:       3,
+		Moves2:       2,
+		ThinkTime1:   3 * time.Millisecond,
+	}
+
+	var csvBuf bytes.Buffer
+	csvLog := NewCSVGameLog(&csvBuf)
+	for i := 0; i < 2; i++ {
+		if err := csvLog.WriteGame(&g); err != nil {
+			t.Fatalf("cannot write CSV: %s", err)
+		}
+	}
+	records, err := csv.NewReader(&csvBuf).ReadAll()
+	if err != nil {
+		t.Fatalf("cannot read CSV: %s", err)
+	}
+	if len(records) != 3 || records[0][0] != "game" || len(records[1]) != len(records[0]) {
+		t.Fatalf("expected header and 2 lines of the same length, but got %q", records)
+	}
+	if records[1][4] != "PENTO_L" || records[1][6] != "PLAYER1" || records[1][10] != "BLUE: player hit timeout" || records[1][13] != "3000000" {
+		t.Errorf("unexpected CSV line %q", records[1])
+	}
+
+	var jsonlBuf bytes.Buffer
+	if err = NewJSONLGameLog(&jsonlBuf).WriteGame(&g); err != nil {
+		t.Fatalf("cannot write JSONL: %s", err)
+	}
+	var line struct {
+		StartPiece string `json:"startPiece"`
+		Error2     struct {
+			Color string `json:"color"`
+			Kind  string `json:"kind"`
+		} `json:"error2"`
+		ThinkTime1 int64 `json:"thinkTime1Ns"`
+	}
+	if err = json.Unmarshal(jsonlBuf.Bytes(), &line); err != nil {
+		t.Fatalf("cannot read JSONL: %s", err)
+	}
+	if line.StartPiece != "PENTO_L" || line.Error2.Color != "BLUE" || line.Error2.Kind != "TIMEOUT" || line.ThinkTime1 != 3000000 {
+		t.Errorf("unexpected JSONL line %s", jsonlBuf.String())
+	}
+}
@@ -0,0 +1,263 @@
+package blokus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// GameRecord contains everything needed to replay a game: the start piece, the players, the seed the game was
+// started with, and all moves in the order they were played, plus the final result.
+type GameRecord struct {
+	StartPiece  Piece
+	Player1Name string
+	Player2Name string
+	Seed        int64
+	Entries     []GameRecordEntry
+	Result      GameResult
+	Score1      uint
+	Score2      uint
+	// Error1 and Error2 contain the reason why a player lost the game because of an error, if any
+	Error1 string
+	Error2 string
+}
+
+// GameRecordEntry is a single move or skip (Move.IsEmpty() == true) of a color
+type GameRecordEntry struct {
+	Color     Color
+	Move      Move
+	ThinkTime time.Duration
+	// Evaluation is reported by players implementing EvaluationReporter
+	Evaluation []Evaluation
+	// SearchStats is reported by players implementing SearchStatsReporter
+	SearchStats *SearchStats
+}
+
+// GameRecordFormatVersion is written into every serialized GameRecord
+const GameRecordFormatVersion = 1
+
+type jsonGameRecord struct {
+	Version     int                   `json:"version"`
+	StartPiece  string                `json:"startPiece"`
+	Player1Name string                `json:"player1,omitempty"`
+	Player2Name string                `json:"player2,omitempty"`
+	Seed        int64                 `json:"seed"`
+	Entries     []jsonGameRecordEntry `json:"moves"`
+	Result      string                `json:"result"`
+	Score1      uint                  `json:"score1"`
+	Score2      uint                  `json:"score2"`
+	Error1      string                `json:"error1,omitempty"`
+	Error2      string                `json:"error2,omitempty"`
+}
+
+type jsonGameRecordEntry struct {
+	Color       string           `json:"color"`
+	Skip        bool             `json:"skip,omitempty"`
+	Piece       string           `json:"piece,omitempty"`
+	Rotation    string           `json:"rotation,omitempty"`
+	Flipped     bool             `json:"flipped,omitempty"`
+	X           uint8            `json:"x"`
+	Y           uint8            `json:"y"`
+	ThinkTime   string           `json:"thinkTime"`
+	Evaluation  []jsonEvaluation `json:"evaluation,omitempty"`
+	SearchStats *jsonSearchStats `json:"searchStats,omitempty"`
+}
+
+type jsonSearchStats struct {
+	Nodes uint64 `json:"nodes"`
+	Depth uint   `json:"depth"`
+}
+
+type jsonEvaluation struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+func (r *GameRecord) MarshalJSON() ([]byte, error) {
+	jr := jsonGameRecord{
+		Version:     GameRecordFormatVersion,
+		StartPiece:  r.StartPiece.String(),
+		Player1Name: r.Player1Name,
+		Player2Name: r.Player2Name,
+		Seed:        r.Seed,
+		Entries:     make([]jsonGameRecordEntry, 0, len(r.Entries)),
+		Result:      r.Result.String(),
+		Score1:      r.Score1,
+		Score2:      r.Score2,
+		Error1:      r.Error1,
+		Error2:      r.Error2,
+	}
+	for _, e := range r.Entries {
+		je := jsonGameRecordEntry{
+			Color:     e.Color.String(),
+			ThinkTime: e.ThinkTime.String(),
+		}
+		for _, ev := range e.Evaluation {
+			je.Evaluation = append(je.Evaluation, jsonEvaluation{Name: ev.Name, Value: ev.Value})
+		}
+		if e.SearchStats != nil {
+			je.SearchStats = &jsonSearchStats{Nodes: e.SearchStats.Nodes, Depth: e.SearchStats.Depth}
+		}
+		if e.Move.IsEmpty() {
+			je.Skip = true
+		} else {
+			je.Piece = e.Move.Transformation.Piece().String()
+			je.Rotation = e.Move.Transformation.Rotation().String()
+			je.Flipped = e.Move.Transformation.Flipped()
+			je.X = e.Move.X
+			je.Y = e.Move.Y
+		}
+		jr.Entries = append(jr.Entries, je)
+	}
+	return json.Marshal(&jr)
+}
+
+func (r *GameRecord) UnmarshalJSON(data []byte) (err error) {
+	var jr jsonGameRecord
+	if err = json.Unmarshal(data, &jr); err != nil {
+		return
+	}
+	if jr.Version != GameRecordFormatVersion {
+		return fmt.Errorf("unsupported game record version %d", jr.Version)
+	}
+	var nr GameRecord
+	if nr.StartPiece, err = ParsePiece(jr.StartPiece); err != nil {
+		return fmt.Errorf("cannot parse startPiece: %s", err)
+	}
+	if nr.Result, err = ParseGameResult(jr.Result); err != nil {
+		return fmt.Errorf("cannot parse result: %s", err)
+	}
+	nr.Player1Name = jr.Player1Name
+	nr.Player2Name = jr.Player2Name
+	nr.Seed = jr.Seed
+	nr.Score1 = jr.Score1
+	nr.Score2 = jr.Score2
+	nr.Error1 = jr.Error1
+	nr.Error2 = jr.Error2
+	nr.Entries = make([]GameRecordEntry, 0, len(jr.Entries))
+	for i, je := range jr.Entries {
+		var e GameRecordEntry
+		if e, err = je.entry(); err != nil {
+			return fmt.Errorf("move %d: %s", i+1, err)
+		}
+		nr.Entries = append(nr.Entries, e)
+	}
+	*r = nr
+	return
+}
+
+func (je *jsonGameRecordEntry) entry() (e GameRecordEntry, err error) {
+	if e.Color, err = ParseColor(je.Color); err != nil {
+		return
+	}
+	if e.ThinkTime, err = time.ParseDuration(je.ThinkTime); err != nil {
+		err = fmt.Errorf("cannot parse thinkTime: %s", err)
+		return
+	}
+	for _, jev := range je.Evaluation {
+		e.Evaluation = append(e.Evaluation, Evaluation{Name: jev.Name, Value: jev.Value})
+	}
+	if je.SearchStats != nil {
+		e.SearchStats = &SearchStats{Nodes: je.SearchStats.Nodes, Depth: je.SearchStats.Depth}
+	}
+	if je.Skip {
+		return
+	}
+	var piece Piece
+	var rotation Rotation
+	if piece, err = ParsePiece(je.Piece); err != nil {
+		return
+	}
+	if rotation, err = ParseRotation(je.Rotation); err != nil {
+		return
+	}
+	e.Move = NewMove(NewTransformedPiece(piece, rotation, je.Flipped), je.X, je.Y)
+	return
+}
+
+// WriteGameRecord writes r as JSON to w
+func WriteGameRecord(w io.Writer, r *GameRecord) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// ReadGameRecord reads a GameRecord written by WriteGameRecord from rd
+func ReadGameRecord(rd io.Reader) (r *GameRecord, err error) {
+	r = new(GameRecord)
+	if err = json.NewDecoder(rd).Decode(r); err != nil {
+		r = nil
+	}
+	return
+}
+
+// SaveGameRecord writes r to the file at path
+func SaveGameRecord(path string, r *GameRecord) (err error) {
+	var f *os.File
+	if f, err = os.Create(path); err != nil {
+		return
+	}
+	if err = WriteGameRecord(f, r); err != nil {
+		f.Close()
+		return
+	}
+	return f.Close()
+}
+
+// LoadGameRecord reads a GameRecord from the file at path
+func LoadGameRecord(path string) (r *GameRecord, err error) {
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		return
+	}
+	defer f.Close()
+	return ReadGameRecord(f)
+}
+
+// Replay reconstructs all states of a recorded game by playing its moves in a Game. states[0] is the initial state
+// and states[i+1] is the state after r.Entries[i]. For each state, CurrentColor() is the color of the next entry, and
+// IsColorValid() is true for the colors that are still in the game.
+// It fails if a move is not allowed, or if an entry is not of the color to move.
+func Replay(r *GameRecord) (states []*BasicState, err error) {
+	states = make([]*BasicState, 0, len(r.Entries)+1)
+	var s BasicState
+	g := NewGame(&s, r.StartPiece)
+	snapshot := func() {
+		cs := new(BasicState)
+		CopyState(cs, &s)
+		states = append(states, cs)
+	}
+	for i, e := range r.Entries {
+		snapshot()
+		if g.IsOver() || e.Color != g.CurrentColor() {
+			err = fmt.Errorf("move %d: %s is not the color to move", i+1, e.Color.String())
+			return
+		}
+		if err = g.Play(e.Move); err != nil {
+			err = fmt.Errorf("move %d of %s: %s", i+1, e.Color.String(), err)
+			return
+		}
+	}
+	snapshot()
+	return
+}
+
+// setResultFrom sets the scores and result of r from the final state s
+func (r *GameRecord) setResultFrom(s State) {
+	r.Result, r.Score1, r.Score2 = OfficialResult(s)
+}
+
+// inferEntries determines the moves played between the states before and after by comparing the boards.
+// If a color became invalid without placing a piece, although it still could have, it is inferred to have skipped.
+func inferEntries(before State, beforeValid map[Color]bool, after State, afterValid map[Color]bool) (entries []GameRecordEntry) {
+	for c := Color(0); c < 4; c++ {
+		if m, found := InferMove(before, after, c); found {
+			entries = append(entries, GameRecordEntry{Color: c, Move: m})
+		} else if beforeValid[c] && !afterValid[c] && before.HasPlayed(c) && HasPossibleNextMoves(before, c) {
+			entries = append(entries, GameRecordEntry{Color: c, Move: EmptyMove})
+		}
+	}
+	return
+}
@@ -0,0 +1,119 @@
+package blokus
+
+import (
+	"bytes"
+	"github.com/hschendel/sc"
+	"testing"
+)
+
+// firstMovePlayer always plays the first possible move
+type firstMovePlayer struct{}
+
+func (f firstMovePlayer) NextMove(state State, color Color, timeout sc.Timeout) Move {
+	moves := PossibleNextMoves(state, color)
+	if len(moves) == 0 {
+		return EmptyMove
+	}
+	return moves[0]
+}
+
+func (f firstMovePlayer) End() {
+}
+
+// evaluatingPlayer is a firstMovePlayer that reports a fixed evaluation and search stats
+type evaluatingPlayer struct {
+	firstMovePlayer
+}
+
+func (e evaluatingPlayer) LastEvaluation() []Evaluation {
+	return []Evaluation{{Name: "answer", Value: 42.5}}
+}
+
+func (e evaluatingPlayer) LastSearchStats() SearchStats {
+	return SearchStats{Nodes: 1000, Depth: 3}
+}
+
+func TestGameRecordRoundTrip(t *testing.T) {
+	var record GameRecord
+	m := Match{
+		Player1:     firstMovePlayer{},
+		Player2:     evaluatingPlayer{},
+		Player1Name: "one",
+		Player2Name: "two",
+		Seed:        42,
+		Record:      &record,
+	}
+	result, score1, score2, err1, err2 := m.Run()
+	if err1 != nil || err2 != nil {
+		t.Fatalf("unexpected errors: %v, %v", err1, err2)
+	}
+	if record.Result != result || record.Score1 != score1 || record.Score2 != score2 {
+		t.Errorf("record result %s %d:%d does not match game result %s %d:%d", record.Result, record.Score1, record.Score2, result, score1, score2)
+	}
+	if record.StartPiece != m.StartPiece() {
+		t.Errorf("expected start piece %s but got %s", m.StartPiece(), record.StartPiece)
+	}
+	if len(record.Entries) < 4 {
+		t.Fatalf("expected at least 4 recorded moves, but got %d", len(record.Entries))
+	}
+
+	var buf bytes.Buffer
+	if err := WriteGameRecord(&buf, &record); err != nil {
+		t.Fatalf("cannot write record: %s", err)
+	}
+	read, err := ReadGameRecord(&buf)
+	if err != nil {
+		t.Fatalf("cannot read record: %s", err)
+	}
+	if read.StartPiece != record.StartPiece || read.Seed != record.Seed || read.Result != record.Result ||
+		read.Player1Name != record.Player1Name || read.Player2Name != record.Player2Name {
+		t.Errorf("read record header differs:\nexpected %+v\ngot %+v", record, *read)
+	}
+	if len(read.Entries) != len(record.Entries) {
+		t.Fatalf("expected %d entries but got %d", len(record.Entries), len(read.Entries))
+	}
+	for i, e := range record.Entries {
+		r := read.Entries[i]
+		if r.Color != e.Color || !r.Move.Equal(e.Move) || r.ThinkTime != e.ThinkTime {
+			t.Errorf("entry %d differs: expected %+v but got %+v", i, e, r)
+		}
+		expectEvaluation := e.Color%2 == 1
+		if hasEvaluation := len(r.Evaluation) == 1 && r.Evaluation[0] == (Evaluation{Name: "answer", Value: 42.5}); hasEvaluation != expectEvaluation {
+			t.Errorf("entry %d: expected evaluation %t but got %+v", i, expectEvaluation, r.Evaluation)
+		}
+		if hasStats := r.SearchStats != nil && *r.SearchStats == (SearchStats{Nodes: 1000, Depth: 3}); hasStats != expectEvaluation {
+			t.Errorf("entry %d: expected search stats %t but got %+v", i, expectEvaluation, r.SearchStats)
+		}
+	}
+
+	states, err := Replay(read)
+	if err != nil {
+		t.Fatalf("cannot replay: %s", err)
+	}
+	if len(states) != len(read.Entries)+1 {
+		t.Fatalf("expected %d states but got %d", len(read.Entries)+1, len(states))
+	}
+	final := states[len(states)-1]
+	var g1, g2 uint
+	for _, c := range OwnColors(ColorBlue) {
+		g1 += OfficialRatingForColor(final, c)
+	}
+	for _, c := range OwnColors(ColorYellow) {
+		g2 += OfficialRatingForColor(final, c)
+	}
+	if g1 != score1 || g2 != score2 {
+		t.Errorf("expected replayed score %d:%d but got %d:%d", score1, score2, g1, g2)
+	}
+	if !HasGameEnded(final) {
+		t.Errorf("expected game to have ended in final replayed state")
+	}
+	for i, e := range read.Entries {
+		if states[i].CurrentColor() != e.Color {
+			t.Errorf("expected current color %s in state %d, but got %s", e.Color, i, states[i].CurrentColor())
+		}
+		m, found := InferMove(states[i], states[i+1], e.Color)
+		if found != !e.Move.IsEmpty() || (found && !m.Equal(e.Move)) {
+			t.Errorf("cannot infer move %d from replayed states", i)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package blokus
+
+import (
+	"testing"
+)
+
+func TestGame_PlayUndo(t *testing.T) {
+	var s BasicState
+	g := NewGame(&s, PieceTrioL)
+	initial := FormatPosition(&s)
+
+	if err := g.Play(EmptyMove); err != ErrForbiddenMove {
+		t.Errorf("expected %v for skipping the first move but got %v", ErrForbiddenMove, err)
+	}
+	if err := g.Play(MustParseMove("TRIO_L:NONE@5,5")); err != ErrForbiddenMove {
+		t.Errorf("expected %v for first move outside a corner but got %v", ErrForbiddenMove, err)
+	}
+	firstMoves := []string{"TRIO_L:NONE@0,0", "TRIO_L:RIGHT@18,0", "TRIO_L:LEFT@18,18", "TRIO_L:NONE:F@0,18"}
+	for i, ms := range firstMoves {
+		if c := g.CurrentColor(); c != Color(i) {
+			t.Fatalf("expected %s to move but got %s", Color(i), c)
+		}
+		if err := g.Play(MustParseMove(ms)); err != nil {
+			t.Fatalf("cannot play %s: %s", ms, err)
+		}
+	}
+	afterFirstRound := FormatPosition(&s)
+	if g.CurrentColor() != ColorBlue || g.Turn() != 4 {
+		t.Errorf("expected BLUE to move in turn 4 but got %s in turn %d", g.CurrentColor(), g.Turn())
+	}
+
+	if err := g.Play(EmptyMove); err != nil {
+		t.Fatalf("cannot skip: %s", err)
+	}
+	if s.IsColorValid(ColorBlue) || g.CurrentColor() != ColorYellow {
+		t.Errorf("expected BLUE to be out of the game after skipping, and YELLOW to move")
+	}
+	for i := 0; i < 3; i++ {
+		if err := g.Play(EmptyMove); err != nil {
+			t.Fatalf("cannot skip: %s", err)
+		}
+	}
+	if !g.IsOver() {
+		t.Errorf("expected game to be over after every color skipped")
+	}
+	if err := g.Play(EmptyMove); err != ErrGameOver {
+		t.Errorf("expected %v but got %v", ErrGameOver, err)
+	}
+	if score1, score2 := g.Scores(); score1 != 6 || score2 != 6 || g.Winner() != GameResultDraw {
+		t.Errorf("expected a draw with 6:6 but got %s with %d:%d", g.Winner(), score1, score2)
+	}
+
+	for i := 0; i < 4; i++ {
+		g.Undo()
+	}
+	if o := FormatPosition(&s); o != afterFirstRound {
+		t.Errorf("expected\n%s\nafter undoing the skips but got\n%s", afterFirstRound, o)
+	}
+	for g.Undo() {
+	}
+	if o := FormatPosition(&s); o != initial {
+		t.Errorf("expected\n%s\nafter undoing everything but got\n%s", initial, o)
+	}
+}
+
+func TestGame_FullGame(t *testing.T) {
+	var s BasicState
+	g := NewGame(&s, PiecePentoL)
+	initial := FormatPosition(&s)
+	for !g.IsOver() {
+		moves := g.LegalMoves()
+		if len(moves) == 0 {
+			t.Fatalf("%s has no legal moves but is still playing", g.CurrentColor())
+		}
+		if err := g.Play(moves[0]); err != nil {
+			t.Fatalf("cannot play legal move %s: %s", moves[0], err)
+		}
+	}
+	for c := Color(0); c < 4; c++ {
+		if HasPossibleNextMoves(&s, c) {
+			t.Errorf("expected %s to have no moves left at the end of the game", c)
+		}
+	}
+	if g.LegalMoves() != nil {
+		t.Errorf("expected no legal moves after the game is over")
+	}
+	for g.Undo() {
+	}
+	if o := FormatPosition(&s); o != initial {
+		t.Errorf("expected\n%s\nafter undoing everything but got\n%s", initial, o)
+	}
+}
+
+func TestResumeGame(t *testing.T) {
+	// red has only the mono left, and there is no free corner for it
+	s := MustParsePosition("20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/G1G17/GR18/R1G17 1fffff,1fffff,1,1fffff - MONO R BYRG 1")
+	g := ResumeGame(s)
+	if s.IsColorValid(ColorRed) || g.CurrentColor() != ColorGreen {
+		t.Errorf("expected RED to be out of the game and GREEN to move, but %s is to move", g.CurrentColor())
+	}
+	if g.Undo() {
+		t.Errorf("expected no moves to undo after resuming")
+	}
+}
+
+func TestMatchSeries_Seed(t *testing.T) {
+	seeds := func(seed int64) (seeds []int64) {
+		s := MatchSeries{Player1: firstMovePlayer{}, Player2: firstMovePlayer{}, Repetitions: 3, Seed: seed}
+		s.OnGame = func(g *SeriesGame) {
+			seeds = append(seeds, g.Seed)
+		}
+		s.Run()
+		return
+	}
+	a, b := seeds(42), seeds(42)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("expected the same game seeds for the same series seed, but got %v and %v", a, b)
+		}
+	}
+	if a[0] == a[1] && a[1] == a[2] {
+		t.Errorf("expected different seeds for the games of a series, but got %v", a)
+	}
+}
+
+func TestMatch_Stop(t *testing.T) {
+	stop := make(chan struct{})
+	m := Match{Player1: firstMovePlayer{}, Player2: firstMovePlayer{}, Seed: 1, Stop: stop, Record: new(GameRecord)}
+	m.OnMove = func(entries []GameRecordEntry) {
+		if len(entries) == 3 {
+			close(stop)
+		}
+	}
+	result, _, _, err1, err2 := m.Run()
+	if result != GameResultDraw || err1 != ErrMatchStopped || err2 != ErrMatchStopped {
+		t.Errorf("expected a draw stopped for both players, but got %s, %v, %v", result, err1, err2)
+	}
+	if len(m.Record.Entries) != 3 {
+		t.Errorf("expected the game to stop after 3 moves, but got %d", len(m.Record.Entries))
+	}
+}
+
+func TestMatch_StartPiece(t *testing.T) {
+	seen := make(map[Piece]bool)
+	for seed := int64(0); seed < 200; seed++ {
+		m := Match{Seed: seed}
+		p := m.StartPiece()
+		if p.NumPoints() != 5 || p == PiecePentoX {
+			t.Fatalf("seed %d: expected a pentomino other than PENTO_X, but got %s", seed, p.String())
+		}
+		seen[p] = true
+	}
+	if len(seen) != len(StartPieces) {
+		t.Errorf("expected all %d start pieces to be chosen, but got %d", len(StartPieces), len(seen))
+	}
+	for _, p := range StartPieces {
+		if len(PossibleNextMoves(NewGame(new(BasicState), p).State(), ColorBlue)) == 0 {
+			t.Errorf("expected %s to be playable as the first move", p.String())
+		}
+	}
+}
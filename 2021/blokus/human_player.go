@@ -0,0 +1,306 @@
+package blokus
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/hschendel/sc"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// HumanPlayer lets a person play by typing commands. For every move, it prints the board with the anchors of the
+// color to play, and the pieces that are left. Then it reads commands from In until a valid move was entered.
+// Type "help" to get a list of commands.
+type HumanPlayer struct {
+	In  io.Reader
+	Out io.Writer
+	// ANSI enables colored output
+	ANSI bool
+	// AllowUndo enables the undo command, which only works in local games run by Match
+	AllowUndo bool
+
+	scanner       *bufio.Scanner
+	undoRequested bool
+	seen          BasicState
+	hasSeen       bool
+	moves         []Move
+}
+
+const humanPlayerHelp = `Commands:
+  <n>                             play move number n of the list shown by "moves"
+  <piece> <rotation> [F] <x> <y>  play a piece, e.g. "pento_l right f 3 7" (F = flipped)
+  <move>                          play a move in move notation, e.g. "pento_l:right:f@3,7"
+  moves [<piece>]                 list possible moves, optionally only for one piece
+  piece <piece>                   show all rotations of a piece
+  skip                            give up this color (not allowed in the first round)
+  undo                            take back your previous move (local games only)
+  board                           show the board again
+`
+
+func (h *HumanPlayer) NextMove(state State, color Color, timeout sc.Timeout) Move {
+	if h.scanner == nil {
+		h.scanner = bufio.NewScanner(h.In)
+	}
+	h.undoRequested = false
+	h.moves = PossibleNextMoves(state, color)
+	h.printSituation(state, color)
+	for {
+		fmt.Fprintf(h.Out, "%s> ", color.String())
+		if !h.scanner.Scan() {
+			fmt.Fprintln(h.Out, "end of input, skipping")
+			return EmptyMove
+		}
+		move, done := h.command(state, color, strings.Fields(h.scanner.Text()))
+		if done {
+			CopyState(&h.seen, state)
+			if !move.IsEmpty() {
+				MustApplyMove(&h.seen, color, move)
+			}
+			h.hasSeen = true
+			return move
+		}
+	}
+}
+
+func (h *HumanPlayer) End() {
+}
+
+func (h *HumanPlayer) UndoRequested() bool {
+	return h.undoRequested
+}
+
+// command executes a command, and returns done = true if it results in a move
+func (h *HumanPlayer) command(s State, c Color, fields []string) (move Move, done bool) {
+	if len(fields) == 0 {
+		return
+	}
+	switch strings.ToLower(fields[0]) {
+	case "help", "?":
+		fmt.Fprint(h.Out, humanPlayerHelp)
+	case "board":
+		h.printSituation(s, c)
+	case "moves":
+		h.printMoves(fields[1:])
+	case "piece":
+		if len(fields) != 2 {
+			fmt.Fprintln(h.Out, "usage: piece <piece>")
+			return
+		}
+		p, err := ParsePiece(strings.ToUpper(fields[1]))
+		if err != nil {
+			fmt.Fprintln(h.Out, err)
+			return
+		}
+		for _, tp := range p.Transformations() {
+			fmt.Fprintln(h.Out, tp.String())
+			tp.PrettyPrint('#', "  ", h.Out)
+		}
+	case "skip":
+		if !CanApplyMove(s, c, EmptyMove) {
+			fmt.Fprintln(h.Out, "you cannot skip your first move")
+			return
+		}
+		return EmptyMove, true
+	case "undo":
+		if !h.AllowUndo {
+			fmt.Fprintln(h.Out, "undo is not possible in this game")
+			return
+		}
+		h.undoRequested = true
+		h.hasSeen = false
+		return EmptyMove, true
+	default:
+		var err error
+		if move, err = h.parseMove(fields); err != nil {
+			fmt.Fprintln(h.Out, err)
+			return
+		}
+		if !CanApplyMove(s, c, move) {
+			fmt.Fprintf(h.Out, "move not allowed:\n%s", move.FormatPretty('#', "  "))
+			return
+		}
+		done = true
+	}
+	return
+}
+
+func (h *HumanPlayer) parseMove(fields []string) (move Move, err error) {
+	if len(fields) == 1 {
+		if strings.ContainsRune(fields[0], '@') {
+			return ParseMove(fields[0])
+		}
+		var i int
+		if i, err = strconv.Atoi(fields[0]); err != nil {
+			err = fmt.Errorf("unknown command %q, type help for a list of commands", fields[0])
+			return
+		}
+		if i < 0 || i >= len(h.moves) {
+			err = fmt.Errorf("there is no move number %d", i)
+			return
+		}
+		move = h.moves[i]
+		return
+	}
+	flipped := false
+	if len(fields) == 5 {
+		if f := strings.ToUpper(fields[2]); f != "F" && f != "FLIPPED" {
+			err = fmt.Errorf("expected F or FLIPPED but got %q", fields[2])
+			return
+		}
+		flipped = true
+		fields = append(fields[:2], fields[3:]...)
+	}
+	if len(fields) != 4 {
+		err = fmt.Errorf("expected <piece> <rotation> [F] <x> <y>")
+		return
+	}
+	var p Piece
+	var r Rotation
+	var x, y uint64
+	if p, err = ParsePiece(strings.ToUpper(fields[0])); err != nil {
+		return
+	}
+	if r, err = ParseRotation(strings.ToUpper(fields[1])); err != nil {
+		return
+	}
+	if x, err = strconv.ParseUint(fields[2], 10, 8); err != nil {
+		err = fmt.Errorf("invalid x: %s", err)
+		return
+	}
+	if y, err = strconv.ParseUint(fields[3], 10, 8); err != nil {
+		err = fmt.Errorf("invalid y: %s", err)
+		return
+	}
+	move = NewMove(NewTransformedPiece(p, r, flipped), uint8(x), uint8(y))
+	return
+}
+
+func (h *HumanPlayer) printSituation(s State, c Color) {
+	var lastMove Move
+	if h.hasSeen {
+		for oc := Color(0); oc < 4; oc++ {
+			if m, found := InferMove(&h.seen, s, oc); found {
+				fmt.Fprintf(h.Out, "%s played %s\n", oc.String(), m.String())
+				lastMove = m
+			}
+		}
+	}
+	fmt.Fprint(h.Out, FormatBoard(s, BoardFormat{
+		ANSI:        h.ANSI,
+		LastMove:    lastMove,
+		ShowAnchors: true,
+		AnchorColor: c,
+	}))
+	fmt.Fprintf(h.Out, "Pieces left for %s:", c.String())
+	for _, p := range s.NotPlayedPiecesFor(c) {
+		fmt.Fprintf(h.Out, " %s", p.String())
+	}
+	fmt.Fprintf(h.Out, "\n%d possible moves. Type help for a list of commands.\n", len(h.moves))
+}
+
+func (h *HumanPlayer) printMoves(args []string) {
+	var filter *Piece
+	if len(args) > 0 {
+		p, err := ParsePiece(strings.ToUpper(args[0]))
+		if err != nil {
+			fmt.Fprintln(h.Out, err)
+			return
+		}
+		filter = &p
+	}
+	for i, m := range h.moves {
+		if filter != nil && m.Transformation.Piece() != *filter {
+			continue
+		}
+		fmt.Fprintf(h.Out, "%4d: %s\n", i, m.String())
+	}
+}
+
+// HumanMain provides the main function for a command line tool that lets a person play against one of the bots,
+// or join a game on the server.
+// You can invoke the executable like this: <name of executable> <name of bot>
+// Add -second to let the bot make the first move, and -record <file> to save the game record.
+// With <name of executable> server [<port>] the human player connects to the server using ClientMain.
+func HumanMain(bots map[string]Player) {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	second := fs.Bool("second", false, "let the bot make the first move")
+	ansi := fs.Bool("ansi", true, "colored output")
+	recordTo := fs.String("record", "", "save the game record to this file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s <bot> [flags]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s server [<port>]\n\nAvailable bots:\n", os.Args[0])
+		for _, botName := range sortedPlayerNames(bots) {
+			fmt.Fprintf(os.Stderr, "  - %s\n", botName)
+		}
+		fmt.Fprintln(os.Stderr, "\nFlags:")
+		fs.PrintDefaults()
+		os.Stderr.Sync()
+	}
+	if len(os.Args) < 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		os.Exit(1)
+	}
+	human := &HumanPlayer{In: os.Stdin, Out: os.Stdout, ANSI: *ansi}
+	if os.Args[1] == "server" {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		ClientMain(human)
+		return
+	}
+	botName := os.Args[1]
+	bot := bots[botName]
+	if bot == nil {
+		fs.Usage()
+		os.Exit(1)
+	}
+	human.AllowUndo = true
+
+	var record GameRecord
+	m := Match{
+		Player1:     human,
+		Player2:     bot,
+		Player1Name: "human",
+		Player2Name: botName,
+		Seed:        RandomSeed(),
+		Record:      &record,
+	}
+	if *second {
+		m.Player1, m.Player2 = m.Player2, m.Player1
+		m.Player1Name, m.Player2Name = m.Player2Name, m.Player1Name
+	}
+	m.Run()
+	printHumanGameResult(os.Stdout, &record, *ansi)
+	if *recordTo != "" {
+		if err := SaveGameRecord(*recordTo, &record); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot save game record: %s\n", err)
+			os.Exit(2)
+		}
+	}
+}
+
+func printHumanGameResult(w io.Writer, record *GameRecord, ansi bool) {
+	if states, err := Replay(record); err == nil {
+		fmt.Fprint(w, FormatBoard(states[len(states)-1], BoardFormat{ANSI: ansi}))
+	}
+	fmt.Fprintf(w, "%s: %d, %s: %d\n", record.Player1Name, record.Score1, record.Player2Name, record.Score2)
+	switch record.Result {
+	case GameResultPlayer1Won:
+		fmt.Fprintf(w, "%s wins\n", record.Player1Name)
+	case GameResultPlayer2Won:
+		fmt.Fprintf(w, "%s wins\n", record.Player2Name)
+	default:
+		fmt.Fprintln(w, "draw")
+	}
+	for _, errMsg := range []string{record.Error1, record.Error2} {
+		if errMsg != "" {
+			fmt.Fprintln(w, errMsg)
+		}
+	}
+}
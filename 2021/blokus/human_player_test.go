@@ -0,0 +1,84 @@
+package blokus
+
+import (
+	"bytes"
+	"github.com/hschendel/sc"
+	"strings"
+	"testing"
+)
+
+func TestHumanPlayer_NextMove(t *testing.T) {
+	var s BasicState
+	s.SetStartPiece(PieceTrioL)
+	cases := []struct {
+		input string
+		e     Move
+	}{
+		{input: "0\n", e: PossibleNextMoves(&s, ColorBlue)[0]},
+		{input: "skip\ntrio_l none 0 0\n", e: NewMove(NewTransformedPiece(PieceTrioL, RotationNone, false), 0, 0)},
+		{input: "TRIO_L NONE F 1 1\nmoves\ntrio_l none f 18 0\n", e: NewMove(NewTransformedPiece(PieceTrioL, RotationNone, true), 18, 0)},
+		{input: "trio_l:right@0,0\n", e: NewMove(NewTransformedPiece(PieceTrioL, RotationRight, false), 0, 0)},
+		{input: "undo\n", e: EmptyMove},
+	}
+	for i, tc := range cases {
+		var out bytes.Buffer
+		h := HumanPlayer{In: strings.NewReader(tc.input), Out: &out}
+		o := h.NextMove(&s, ColorBlue, sc.NewTimeout(DefaultMoveTimeout))
+		if !o.Equal(tc.e) {
+			t.Errorf("case %d failed: expected\n%s\nbut got\n%s\noutput:\n%s", i, tc.e.FormatPretty('E', "  "), o.FormatPretty('G', "  "), out.String())
+		}
+		if h.UndoRequested() {
+			t.Errorf("case %d: expected no undo request without AllowUndo", i)
+		}
+	}
+}
+
+// undoingPlayer plays like firstMovePlayer, but requests an undo on its second move
+type undoingPlayer struct {
+	calls int
+	undo  bool
+}
+
+func (u *undoingPlayer) NextMove(state State, color Color, timeout sc.Timeout) Move {
+	u.calls++
+	u.undo = u.calls == 2
+	return firstMovePlayer{}.NextMove(state, color, timeout)
+}
+
+func (u *undoingPlayer) End() {
+}
+
+func (u *undoingPlayer) UndoRequested() bool {
+	return u.undo
+}
+
+func TestMatchUndo(t *testing.T) {
+	var withUndo, withoutUndo GameRecord
+	player := new(undoingPlayer)
+	m := Match{Player1: player, Player2: firstMovePlayer{}, Seed: 1, Record: &withUndo}
+	m.Run()
+	m = Match{Player1: firstMovePlayer{}, Player2: firstMovePlayer{}, Seed: 1, Record: &withoutUndo}
+	m.Run()
+
+	player1Moves := 0
+	for _, e := range withUndo.Entries {
+		if e.Color%2 == 0 {
+			player1Moves++
+		}
+	}
+	// the undone move and the move that requested the undo are not recorded
+	if player.calls != player1Moves+2 {
+		t.Errorf("expected %d calls of the interactive player but got %d", player1Moves+2, player.calls)
+	}
+	if len(withUndo.Entries) != len(withoutUndo.Entries) {
+		t.Fatalf("expected %d moves but got %d", len(withoutUndo.Entries), len(withUndo.Entries))
+	}
+	for i, e := range withoutUndo.Entries {
+		if withUndo.Entries[i].Color != e.Color || !withUndo.Entries[i].Move.Equal(e.Move) {
+			t.Errorf("move %d differs", i)
+		}
+	}
+	if _, err := Replay(&withUndo); err != nil {
+		t.Errorf("cannot replay game with undo: %s", err)
+	}
+}
@@ -40,7 +40,7 @@ func (m Move) Equal(o Move) bool {
 	if m.X != o.X || m.Y != o.Y {
 		return false
 	}
-	return PositionsEqual(m.Transformation.Positions(), o.Transformation.Positions())
+	return ShapeOf(m.Transformation).Mask == ShapeOf(o.Transformation).Mask
 }
 
 // MovesEqual returns true if two slices of distinct moves contain the same moves
@@ -92,8 +92,9 @@ func ApplyMove(s MutableState, c Color, m Move) error {
 		}
 	}
 	s.SetPiecePlayed(c, m.Transformation.Piece(), true)
-	if m.Transformation.Piece() == PieceMono {
-		s.SetLastMoveMono(c, true)
+	// like the official server, only remember whether the last piece was the mono when all pieces are played
+	if len(s.NotPlayedPiecesFor(c)) == 0 {
+		s.SetLastMoveMono(c, m.Transformation.Piece() == PieceMono)
 	}
 	return nil
 }
@@ -116,10 +117,10 @@ func UndoMove(s MutableState, c Color, m Move) {
 			}
 		}
 	}
-	s.SetPiecePlayed(c, m.Transformation.Piece(), false)
-	if m.Transformation.Piece() == PieceMono {
+	if len(s.NotPlayedPiecesFor(c)) == 0 {
 		s.SetLastMoveMono(c, false)
 	}
+	s.SetPiecePlayed(c, m.Transformation.Piece(), false)
 }
 
 func CanApplyMove(s State, c Color, m Move) bool {
@@ -213,6 +214,30 @@ func HasAdjacentWithColor(s State, c Color, x, y uint8) bool {
 		HasColorAt(s, c, x+1, y+1)
 }
 
+// IsAnchor is true if color c could cover the free cell x,y with its next piece, as far as the neighboring cells
+// are concerned. Before c has played, only free start corners are anchors.
+func IsAnchor(s State, c Color, x, y uint8) bool {
+	if _, hasPiece := s.At(x, y); hasPiece || x >= 20 || y >= 20 {
+		return false
+	}
+	if !s.HasPlayed(c) {
+		return IsStartCorner(x, y)
+	}
+	return HasAdjacentWithColor(s, c, x, y) && !HasDirectNeighborWithColor(s, c, x, y)
+}
+
+// Anchors returns all cells where IsAnchor is true for c
+func Anchors(s State, c Color) (anchors []Position) {
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			if IsAnchor(s, c, x, y) {
+				anchors = append(anchors, Position{X: x, Y: y})
+			}
+		}
+	}
+	return
+}
+
 func HasColorAt(s State, c Color, x, y uint8) bool {
 	if x >= 20 || y >= 20 {
 		return false
@@ -224,7 +249,8 @@ func HasColorAt(s State, c Color, x, y uint8) bool {
 	return filledColor == c
 }
 
-func possibleFirstMoves(s State) (moves []Move) {
+// forEachFirstMove calls f for every possible first move until f returns false
+func forEachFirstMove(s State, f func(m Move) bool) bool {
 	for _, tp := range uniquePieceTransformations[s.StartPiece()] {
 		for _, cornerPos := range StartCorners {
 			if cornerPos.X > 0 {
@@ -234,21 +260,70 @@ func possibleFirstMoves(s State) (moves []Move) {
 				cornerPos.Y -= tp.Height() - 1
 			}
 			if canPlayFirstPiece(s, tp, cornerPos.X, cornerPos.Y) {
-				move := NewMove(tp, cornerPos.X, cornerPos.Y)
-				moves = append(moves, move)
+				if !f(NewMove(tp, cornerPos.X, cornerPos.Y)) {
+					return false
+				}
 			}
 		}
 	}
+	return true
+}
+
+// HasPossibleNextMoves returns true if c can place a piece. It stops at the first possible move.
+func HasPossibleNextMoves(s State, c Color) (found bool) {
+	ForEachPossibleNextMove(s, c, func(Move) bool {
+		found = true
+		return false
+	})
 	return
 }
 
-func HasPossibleNextMoves(s State, c Color) bool {
-	return len(PossibleNextMoves(s, c)) > 0
+// CountPossibleNextMoves returns the number of moves PossibleNextMoves would return, without storing them
+func CountPossibleNextMoves(s State, c Color) (n int) {
+	ForEachPossibleNextMove(s, c, func(Move) bool {
+		n++
+		return true
+	})
+	return
 }
 
 func PossibleNextMoves(s State, c Color) (moves []Move) {
+	return AppendPossibleNextMoves(nil, s, c)
+}
+
+// AppendPossibleNextMoves appends the moves of PossibleNextMoves to moves, and returns the extended slice. It only
+// allocates if the capacity of moves is not sufficient, so passing moves[:0] of the previous call avoids allocations.
+func AppendPossibleNextMoves(moves []Move, s State, c Color) []Move {
+	ForEachPossibleNextMove(s, c, func(m Move) bool {
+		moves = append(moves, m)
+		return true
+	})
+	return moves
+}
+
+// MoveList is a buffer for possible moves that is reused between calls of Generate, so that it does not allocate
+// once its capacity is large enough. The zero value is ready to use.
+type MoveList struct {
+	Moves []Move
+}
+
+// NewMoveList returns a MoveList with capacity for n moves
+func NewMoveList(n int) *MoveList {
+	return &MoveList{Moves: make([]Move, 0, n)}
+}
+
+// Generate replaces the moves in l with the possible next moves of c, and returns them. The result is only valid
+// until the next call.
+func (l *MoveList) Generate(s State, c Color) []Move {
+	l.Moves = AppendPossibleNextMoves(l.Moves[:0], s, c)
+	return l.Moves
+}
+
+// ForEachPossibleNextMove calls f for every move of PossibleNextMoves, in the same order, until f returns false.
+// It returns false if f stopped it.
+func ForEachPossibleNextMove(s State, c Color, f func(m Move) bool) bool {
 	if !s.HasPlayed(c) {
-		return possibleFirstMoves(s)
+		return forEachFirstMove(s, f)
 	}
 	pieces := s.NotPlayedPiecesFor(c)
 	scX, scY, started := StartCorner(s, c)
@@ -276,24 +351,27 @@ func PossibleNextMoves(s State, c Color) (moves []Move) {
 			if cc, cFound := s.At(x, rY); cFound && cc == c {
 				colorFound = true
 			}
-			moves = addPlayableMoves(s, pieces, c, shiftLeft, shiftUp, x, rY, moves)
+			if !forEachPlayableMove(s, pieces, c, shiftLeft, shiftUp, x, rY, f) {
+				return false
+			}
 		}
 		for y := startY; y != rY; y += stepY {
 			if cc, cFound := s.At(rX, y); cFound && cc == c {
 				colorFound = true
 			}
-			moves = addPlayableMoves(s, pieces, c, shiftLeft, shiftUp, rX, y, moves)
+			if !forEachPlayableMove(s, pieces, c, shiftLeft, shiftUp, rX, y, f) {
+				return false
+			}
 		}
 		if !colorFound {
 			// cut-off: there will be no more moves with a higher radius
 			break
 		}
 	}
-	return
+	return true
 }
 
-func addPlayableMoves(s State, pieces []Piece, c Color, shiftLeft, shiftUp bool, x, y uint8, moves []Move) (movesResult []Move) {
-	movesResult = moves
+func forEachPlayableMove(s State, pieces []Piece, c Color, shiftLeft, shiftUp bool, x, y uint8, f func(m Move) bool) bool {
 	for _, p := range pieces {
 		for _, tp := range uniquePieceTransformations[p] {
 			tx, ty := x, y
@@ -304,12 +382,13 @@ func addPlayableMoves(s State, pieces []Piece, c Color, shiftLeft, shiftUp bool,
 				ty -= tp.Height() - 1
 			}
 			if CanPlayNextPiece(s, c, tp, tx, ty) {
-				move := NewMove(tp, tx, ty)
-				movesResult = append(movesResult, move)
+				if !f(NewMove(tp, tx, ty)) {
+					return false
+				}
 			}
 		}
 	}
-	return
+	return true
 }
 
 func applyRadiusInc(radius uint8) (start, end, step, fixed uint8) {
@@ -344,3 +423,44 @@ func possibleNextMovesSimple(s State, c Color) (moves []Move) {
 	}
 	return
 }
+
+// InferMove determines the move that color c has played between the states before and after, by comparing
+// the boards. found is false if c has not placed a piece in between.
+func InferMove(before, after State, c Color) (m Move, found bool) {
+	var positions []Position
+	minX, minY := uint8(19), uint8(19)
+	for x := uint8(0); x < 20; x++ {
+		for y := uint8(0); y < 20; y++ {
+			if !HasColorAt(after, c, x, y) || HasColorAt(before, c, x, y) {
+				continue
+			}
+			positions = append(positions, Position{X: x, Y: y})
+			if x < minX {
+				minX = x
+			}
+			if y < minY {
+				minY = y
+			}
+		}
+	}
+	if len(positions) == 0 {
+		return
+	}
+	for i := range positions {
+		positions[i].X -= minX
+		positions[i].Y -= minY
+	}
+	for _, p := range AllPieces {
+		if p.NumPoints() != uint(len(positions)) {
+			continue
+		}
+		for _, tp := range uniquePieceTransformations[p] {
+			if PositionsEqual(tp.Positions(), positions) {
+				m = NewMove(tp, minX, minY)
+				found = true
+				return
+			}
+		}
+	}
+	return
+}
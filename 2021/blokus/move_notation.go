@@ -0,0 +1,90 @@
+package blokus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SkipNotation is the notation of the empty move, see Move.String
+const SkipNotation = "SKIP"
+
+// String returns the move in the notation <piece>:<rotation>[:F]@<x>,<y>, e.g. PENTO_L:RIGHT:F@3,7, using the
+// names of the protocol for pieces and rotations. F marks a flipped piece. The empty move is SKIP.
+// The canonical transformation is used, so moves that are Equal have the same notation.
+func (m Move) String() string {
+	if m.IsEmpty() {
+		return SkipNotation
+	}
+	tp := m.Transformation.Canonical()
+	flipped := ""
+	if tp.Flipped() {
+		flipped = ":F"
+	}
+	return fmt.Sprintf("%s:%s%s@%d,%d", tp.Piece().String(), tp.Rotation().String(), flipped, m.X, m.Y)
+}
+
+// ParseMove parses a move in the notation of Move.String. Upper and lower case are accepted, and FLIPPED
+// instead of F.
+func ParseMove(s string) (m Move, err error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == SkipNotation {
+		return
+	}
+	at := strings.IndexByte(s, '@')
+	if at < 0 {
+		err = fmt.Errorf("invalid move %q: missing @", s)
+		return
+	}
+	parts := strings.Split(s[:at], ":")
+	coordinates := strings.Split(s[at+1:], ",")
+	if len(parts) < 2 || len(parts) > 3 || len(coordinates) != 2 {
+		err = fmt.Errorf("invalid move %q: expected <piece>:<rotation>[:F]@<x>,<y>", s)
+		return
+	}
+	var p Piece
+	var r Rotation
+	if p, err = ParsePiece(parts[0]); err != nil {
+		return
+	}
+	if r, err = ParseRotation(parts[1]); err != nil {
+		return
+	}
+	flipped := false
+	if len(parts) == 3 {
+		if parts[2] != "F" && parts[2] != "FLIPPED" {
+			err = fmt.Errorf("invalid move %q: expected F but got %q", s, parts[2])
+			return
+		}
+		flipped = true
+	}
+	var x, y uint64
+	if x, err = strconv.ParseUint(strings.TrimSpace(coordinates[0]), 10, 8); err != nil || x >= 20 {
+		err = fmt.Errorf("invalid move %q: invalid x %q", s, coordinates[0])
+		return
+	}
+	if y, err = strconv.ParseUint(strings.TrimSpace(coordinates[1]), 10, 8); err != nil || y >= 20 {
+		err = fmt.Errorf("invalid move %q: invalid y %q", s, coordinates[1])
+		return
+	}
+	m = NewMove(NewTransformedPiece(p, r, flipped), uint8(x), uint8(y))
+	return
+}
+
+// MustParseMove is like ParseMove, but panics if s cannot be parsed. It is meant for tests.
+func MustParseMove(s string) Move {
+	m, err := ParseMove(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m Move) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Move) UnmarshalText(text []byte) (err error) {
+	*m, err = ParseMove(string(text))
+	return
+}
@@ -0,0 +1,83 @@
+package blokus
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMove_String(t *testing.T) {
+	cases := []struct {
+		m Move
+		e string
+	}{
+		{m: EmptyMove, e: "SKIP"},
+		{m: NewMove(NewTransformedPiece(PiecePentoL, RotationRight, true), 3, 7), e: "PENTO_L:RIGHT:F@3,7"},
+		{m: NewMove(NewTransformedPiece(PieceMono, RotationLeft, true), 0, 19), e: "MONO:NONE@0,19"},
+		{m: NewMove(NewTransformedPiece(PiecePentoX, RotationMirror, false), 10, 10), e: "PENTO_X:NONE@10,10"},
+		{m: NewMove(NewTransformedPiece(PieceDomino, RotationMirror, true), 1, 2), e: "DOMINO:NONE@1,2"},
+	}
+	for _, tc := range cases {
+		if o := tc.m.String(); o != tc.e {
+			t.Errorf("expected %q but got %q", tc.e, o)
+		}
+	}
+}
+
+func TestParseMove(t *testing.T) {
+	// every transformation of every piece must survive the round trip
+	for p := PieceMono; p < NumPieces; p++ {
+		for r := RotationNone; r < 4; r++ {
+			for _, flipped := range []bool{false, true} {
+				m := NewMove(NewTransformedPiece(p, r, flipped), 5, 17)
+				o, err := ParseMove(m.String())
+				if err != nil {
+					t.Errorf("cannot parse %q: %s", m.String(), err)
+					continue
+				}
+				if !o.Equal(m) || o.String() != m.String() {
+					t.Errorf("%q was parsed as %q", m.String(), o.String())
+				}
+			}
+		}
+	}
+
+	valid := map[string]string{
+		"skip":                    "SKIP",
+		" pento_l:right:f@3,7 ":   "PENTO_L:RIGHT:F@3,7",
+		"TRIO_L:LEFT:FLIPPED@0,0": NewMove(NewTransformedPiece(PieceTrioL, RotationLeft, true), 0, 0).String(),
+	}
+	for s, e := range valid {
+		m, err := ParseMove(s)
+		if err != nil {
+			t.Errorf("cannot parse %q: %s", s, err)
+		} else if m.String() != e {
+			t.Errorf("expected %q to be parsed as %q but got %q", s, e, m.String())
+		}
+	}
+
+	invalid := []string{"", "PENTO_L", "PENTO_L:RIGHT", "PENTO_L@3,7", "PENTO_Q:NONE@1,1", "PENTO_L:UP@1,1",
+		"PENTO_L:NONE:X@1,1", "PENTO_L:NONE@1", "PENTO_L:NONE@20,1", "PENTO_L:NONE@1,-1"}
+	for _, s := range invalid {
+		if _, err := ParseMove(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestMove_MarshalText(t *testing.T) {
+	moves := []Move{EmptyMove, MustParseMove("PENTO_W:RIGHT:F@12,4")}
+	data, err := json.Marshal(moves)
+	if err != nil {
+		t.Fatalf("cannot marshal: %s", err)
+	}
+	if string(data) != `["SKIP","PENTO_W:RIGHT:F@12,4"]` {
+		t.Errorf("unexpected JSON %s", data)
+	}
+	var read []Move
+	if err = json.Unmarshal(data, &read); err != nil {
+		t.Fatalf("cannot unmarshal: %s", err)
+	}
+	if !MovesEqual(read, moves) {
+		t.Errorf("expected %v but got %v", moves, read)
+	}
+}
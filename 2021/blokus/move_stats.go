@@ -0,0 +1,77 @@
+package blokus
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// NearTimeoutRatio is the part of the move timeout after which a move counts as a near timeout in MoveStats
+const NearTimeoutRatio = 0.9
+
+// MoveStats summarizes the think times and search stats of the moves of a player over a MatchSeries. A move that lost
+// the game because of an error counts with its think time, see GameError.ThinkTime.
+type MoveStats struct {
+	Moves         uint          `json:"moves"`
+	MeanThinkTime time.Duration `json:"meanThinkTimeNs"`
+	P95ThinkTime  time.Duration `json:"p95ThinkTimeNs"`
+	MaxThinkTime  time.Duration `json:"maxThinkTimeNs"`
+	// NearTimeouts counts the moves that took longer than NearTimeoutRatio of the move timeout
+	NearTimeouts uint `json:"nearTimeouts"`
+	// SearchMoves counts the moves for which the player reported SearchStats
+	SearchMoves uint    `json:"searchMoves"`
+	TotalNodes  uint64  `json:"totalNodes"`
+	MeanDepth   float64 `json:"meanDepth"`
+	MaxDepth    uint    `json:"maxDepth"`
+
+	thinkTimes []time.Duration
+	totalDepth uint
+}
+
+func (s *MoveStats) add(e *GameRecordEntry, nearTimeout time.Duration) {
+	s.Moves++
+	s.thinkTimes = append(s.thinkTimes, e.ThinkTime)
+	if e.ThinkTime > nearTimeout {
+		s.NearTimeouts++
+	}
+	if e.SearchStats != nil {
+		s.SearchMoves++
+		s.TotalNodes += e.SearchStats.Nodes
+		s.totalDepth += e.SearchStats.Depth
+		if e.SearchStats.Depth > s.MaxDepth {
+			s.MaxDepth = e.SearchStats.Depth
+		}
+	}
+}
+
+// finish calculates the mean and percentile values from the moves added
+func (s *MoveStats) finish() {
+	if s.Moves == 0 {
+		return
+	}
+	sort.Slice(s.thinkTimes, func(i, j int) bool { return s.thinkTimes[i] < s.thinkTimes[j] })
+	var total time.Duration
+	for _, t := range s.thinkTimes {
+		total += t
+	}
+	s.MeanThinkTime = total / time.Duration(s.Moves)
+	s.P95ThinkTime = s.thinkTimes[(len(s.thinkTimes)*95+99)/100-1]
+	s.MaxThinkTime = s.thinkTimes[len(s.thinkTimes)-1]
+	if s.SearchMoves > 0 {
+		s.MeanDepth = float64(s.totalDepth) / float64(s.SearchMoves)
+	}
+}
+
+func (s *MoveStats) print(w io.Writer, playerName string) {
+	if s.Moves == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Think time %s:  mean %s, p95 %s, max %s, %d of %d moves near timeout\n", playerName,
+		s.MeanThinkTime.Round(time.Microsecond), s.P95ThinkTime.Round(time.Microsecond),
+		s.MaxThinkTime.Round(time.Microsecond), s.NearTimeouts, s.Moves)
+	if s.SearchMoves > 0 {
+		fmt.Fprintf(w, "Search %s:      %d nodes (%.1f per move), depth mean %.1f, max %d\n", playerName,
+			s.TotalNodes, float64(s.TotalNodes)/float64(s.SearchMoves), s.MeanDepth, s.MaxDepth)
+	}
+}
@@ -0,0 +1,79 @@
+package blokus
+
+import (
+	"github.com/hschendel/sc"
+	"testing"
+	"time"
+)
+
+func TestMoveStats(t *testing.T) {
+	var s MoveStats
+	for i := 1; i <= 100; i++ {
+		e := GameRecordEntry{ThinkTime: time.Duration(i) * time.Millisecond}
+		if i%2 == 0 {
+			e.SearchStats = &SearchStats{Nodes: 10, Depth: uint(i % 5)}
+		}
+		s.add(&e, 90*time.Millisecond)
+	}
+	s.finish()
+	if s.Moves != 100 || s.MaxThinkTime != 100*time.Millisecond || s.P95ThinkTime != 95*time.Millisecond {
+		t.Errorf("expected 100 moves with max 100ms and p95 95ms but got %d moves, max %s, p95 %s", s.Moves, s.MaxThinkTime, s.P95ThinkTime)
+	}
+	if s.MeanThinkTime != 50500*time.Microsecond {
+		t.Errorf("expected mean think time 50.5ms but got %s", s.MeanThinkTime)
+	}
+	if s.NearTimeouts != 10 {
+		t.Errorf("expected 10 near timeouts but got %d", s.NearTimeouts)
+	}
+	if s.SearchMoves != 50 || s.TotalNodes != 500 || s.MaxDepth != 4 || s.MeanDepth != 2 {
+		t.Errorf("unexpected search stats: %d moves, %d nodes, max depth %d, mean depth %.2f", s.SearchMoves, s.TotalNodes, s.MaxDepth, s.MeanDepth)
+	}
+}
+
+func TestMatchSeriesMoveStats(t *testing.T) {
+	s := MatchSeries{Player1: firstMovePlayer{}, Player2: evaluatingPlayer{}, Repetitions: 2}
+	result := s.Run()
+	if result.Player1MoveStats.Moves == 0 || result.Player1MoveStats.SearchMoves != 0 {
+		t.Errorf("expected moves without search stats for player 1 but got %+v", result.Player1MoveStats)
+	}
+	if result.Player2MoveStats.SearchMoves != result.Player2MoveStats.Moves || result.Player2MoveStats.MaxDepth != 3 {
+		t.Errorf("expected search stats for all moves of player 2 but got %+v", result.Player2MoveStats)
+	}
+}
+
+// slowPlayer plays like firstMovePlayer, but sleeps for delay from its second move on
+type slowPlayer struct {
+	delay time.Duration
+	moves int
+}
+
+func (p *slowPlayer) NextMove(state State, color Color, timeout sc.Timeout) Move {
+	if p.moves++; p.moves > 1 {
+		time.Sleep(p.delay)
+	}
+	return firstMovePlayer{}.NextMove(state, color, timeout)
+}
+
+func (p *slowPlayer) End() {
+}
+
+func TestMatchSeriesMoveStatsTimeout(t *testing.T) {
+	slow := &slowPlayer{delay: 30 * time.Millisecond}
+	s := MatchSeries{Player1: slow, Player2: firstMovePlayer{}, Repetitions: 1, MoveTimeout: 10 * time.Millisecond}
+	var game *SeriesGame
+	s.OnGame = func(g *SeriesGame) {
+		game = g
+	}
+	result := s.Run()
+	if result.Player1Timeouts != 1 {
+		t.Fatalf("expected player 1 to lose by timeout, but got %d timeouts", result.Player1Timeouts)
+	}
+	// the first move was recorded, the second one lost the game
+	stats := result.Player1MoveStats
+	if stats.Moves != 2 || stats.NearTimeouts != 1 || stats.MaxThinkTime < slow.delay {
+		t.Errorf("expected the move hitting the timeout to count, but got %+v", stats)
+	}
+	if game.Moves1 != 2 || game.MaxThinkTime1 < slow.delay {
+		t.Errorf("expected the move hitting the timeout to count for the game, but got %d moves, max %s", game.Moves1, game.MaxThinkTime1)
+	}
+}
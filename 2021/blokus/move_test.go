@@ -147,3 +147,73 @@ func earlyTestState() *BasicState {
 	MustApplyMove(s, ColorGreen, NewMove(NewTransformedPiece(PiecePentoY, RotationRight, true), 14, 16))
 	return s
 }
+
+func TestPossibleNextMovesVariants(t *testing.T) {
+	for _, s := range []State{NewGame(new(BasicState), PiecePentoL).State(), earlyTestState()} {
+		expected := PossibleNextMoves(s, ColorRed)
+		if n := CountPossibleNextMoves(s, ColorRed); n != len(expected) {
+			t.Errorf("expected count %d but got %d", len(expected), n)
+		}
+		var l MoveList
+		if moves := l.Generate(s, ColorRed); !MovesEqual(moves, expected) {
+			t.Errorf("expected MoveList to contain the same moves")
+		}
+		buf := make([]Move, 3, 500)
+		if moves := AppendPossibleNextMoves(buf, s, ColorRed); len(moves) != 3+len(expected) || !MovesEqual(moves[3:], expected) {
+			t.Errorf("expected the moves to be appended")
+		}
+		var visited int
+		if ForEachPossibleNextMove(s, ColorRed, func(m Move) bool {
+			visited++
+			return visited < 2
+		}) || visited != 2 {
+			t.Errorf("expected ForEachPossibleNextMove to stop after 2 moves, but visited %d", visited)
+		}
+		if !HasPossibleNextMoves(s, ColorRed) {
+			t.Errorf("expected possible moves")
+		}
+	}
+}
+
+func TestPossibleNextMovesVariants_NoAllocations(t *testing.T) {
+	s := earlyTestState()
+	l := NewMoveList(500)
+	allocs := testing.AllocsPerRun(10, func() {
+		l.Generate(s, ColorBlue)
+		CountPossibleNextMoves(s, ColorBlue)
+		HasPossibleNextMoves(s, ColorBlue)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations but got %.1f", allocs)
+	}
+}
+
+func BenchmarkMoveListEarly(b *testing.B) {
+	s := earlyTestState()
+	var l MoveList
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		benchmarkNextMovesImplResult = l.Generate(s, ColorBlue)
+	}
+}
+
+var benchmarkCountPossibleNextMovesResult int
+
+func BenchmarkCountPossibleNextMovesEarly(b *testing.B) {
+	s := earlyTestState()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		benchmarkCountPossibleNextMovesResult = CountPossibleNextMoves(s, ColorBlue)
+	}
+}
+
+func BenchmarkHasPossibleNextMovesEarly(b *testing.B) {
+	s := earlyTestState()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		HasPossibleNextMoves(s, ColorBlue)
+	}
+}
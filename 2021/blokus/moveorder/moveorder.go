@@ -0,0 +1,114 @@
+// Package moveorder sorts moves so that a search looks at the most promising moves first. All functions sort in
+// place and do not allocate.
+//
+// Moves are compared by their transformation and coordinates, so they should come from the same move generator, e.g.
+// blokus.PossibleNextMoves, which always uses the same transformation for the same shape.
+package moveorder
+
+import (
+	"github.com/hschendel/sc/2021/blokus"
+)
+
+// PieceSize scores larger pieces higher. The empty move scores 0.
+func PieceSize(m blokus.Move) int64 {
+	if !m.IsMove {
+		return 0
+	}
+	return int64(m.Transformation.Piece().NumPoints())
+}
+
+// TowardCenter scores moves higher the further they reach away from the start corner of the color, as given by
+// blokus.ColorDirection. The score is between 0 and 38.
+func TowardCenter(goDown, goRight bool, m blokus.Move) int64 {
+	if !m.IsMove {
+		return 0
+	}
+	var progress int64
+	if goDown {
+		progress += int64(m.Y + m.Transformation.Height() - 1)
+	} else {
+		progress += int64(19 - m.Y)
+	}
+	if goRight {
+		progress += int64(m.X + m.Transformation.Width() - 1)
+	} else {
+		progress += int64(19 - m.X)
+	}
+	return progress
+}
+
+// SortByPieceSize sorts larger pieces first
+func SortByPieceSize(moves []blokus.Move) {
+	sortByKey(moves, PieceSize)
+}
+
+// SortBySizeAndDirection sorts larger pieces first, and pieces of the same size by TowardCenter
+func SortBySizeAndDirection(s blokus.State, c blokus.Color, moves []blokus.Move) {
+	goDown, goRight := blokus.ColorDirection(s, c)
+	sortByKey(moves, func(m blokus.Move) int64 {
+		return PieceSize(m)*64 + TowardCenter(goDown, goRight, m)
+	})
+}
+
+// Sort sorts the moves with the highest score first
+func Sort(moves []blokus.Move, score func(m blokus.Move) int64) {
+	sortByKey(moves, score)
+}
+
+func sameMove(a, b blokus.Move) bool {
+	return a.IsMove == b.IsMove && a.Transformation == b.Transformation && a.X == b.X && a.Y == b.Y
+}
+
+// sortByKey sorts moves descending by key with a three-way quicksort, as there are usually many equal keys, and
+// insertion sort for short ranges
+func sortByKey(moves []blokus.Move, key func(m blokus.Move) int64) {
+	for len(moves) > 12 {
+		pivot := median(key(moves[0]), key(moves[len(moves)/2]), key(moves[len(moves)-1]))
+		// moves[:greater] have a higher key than pivot, moves[less:] a lower one
+		greater, i, less := 0, 0, len(moves)
+		for i < less {
+			k := key(moves[i])
+			switch {
+			case k > pivot:
+				moves[greater], moves[i] = moves[i], moves[greater]
+				greater++
+				i++
+			case k < pivot:
+				less--
+				moves[i], moves[less] = moves[less], moves[i]
+			default:
+				i++
+			}
+		}
+		// recurse into the smaller part, so that the stack stays small
+		if greater < len(moves)-less {
+			sortByKey(moves[:greater], key)
+			moves = moves[less:]
+		} else {
+			sortByKey(moves[less:], key)
+			moves = moves[:greater]
+		}
+	}
+	for i := 1; i < len(moves); i++ {
+		m := moves[i]
+		k := key(m)
+		j := i
+		for ; j > 0 && key(moves[j-1]) < k; j-- {
+			moves[j] = moves[j-1]
+		}
+		moves[j] = m
+	}
+}
+
+func median(a, b, c int64) int64 {
+	if a > b {
+		a, b = b, a
+	}
+	if b > c {
+		b = c
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,126 @@
+package moveorder
+
+import (
+	"github.com/hschendel/sc/2021/blokus"
+	"math/rand"
+	"testing"
+)
+
+// testPosition returns a position after a few moves of each color, and the possible moves of blue in it
+func testPosition() (blokus.State, []blokus.Move) {
+	g := blokus.NewGame(new(blokus.BasicState), blokus.PiecePentoL)
+	for i := 0; i < 8; i++ {
+		moves := g.LegalMoves()
+		if err := g.Play(moves[i%len(moves)]); err != nil {
+			panic(err)
+		}
+	}
+	return g.State(), blokus.PossibleNextMoves(g.State(), blokus.ColorBlue)
+}
+
+func isSorted(moves []blokus.Move, key func(m blokus.Move) int64) bool {
+	for i := 1; i < len(moves); i++ {
+		if key(moves[i-1]) < key(moves[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSort(t *testing.T) {
+	_, moves := testPosition()
+	rnd := rand.New(rand.NewSource(1))
+	keys := make(map[blokus.Move]int64, len(moves))
+	for _, m := range moves {
+		keys[m] = int64(rnd.Intn(10))
+	}
+	key := func(m blokus.Move) int64 { return keys[m] }
+	sorted := append([]blokus.Move(nil), moves...)
+	Sort(sorted, key)
+	if !isSorted(sorted, key) {
+		t.Errorf("expected moves to be sorted")
+	}
+	if !blokus.MovesEqual(sorted, moves) {
+		t.Errorf("expected sorting to keep all moves")
+	}
+}
+
+func TestSortBySizeAndDirection(t *testing.T) {
+	s, moves := testPosition()
+	SortBySizeAndDirection(s, blokus.ColorBlue, moves)
+	if !isSorted(moves, PieceSize) {
+		t.Errorf("expected larger pieces first")
+	}
+	goDown, goRight := blokus.ColorDirection(s, blokus.ColorBlue)
+	first := moves[0]
+	for _, m := range moves {
+		if PieceSize(m) == PieceSize(first) && TowardCenter(goDown, goRight, m) > TowardCenter(goDown, goRight, first) {
+			t.Errorf("expected %s to be sorted before %s", m.String(), first.String())
+		}
+	}
+}
+
+func TestOrderer(t *testing.T) {
+	s, moves := testPosition()
+	o := NewOrderer()
+	small := moves[len(moves)-1]
+	if PieceSize(small) >= PieceSize(moves[0]) {
+		t.Fatalf("expected moves of different piece sizes")
+	}
+	o.AddKiller(3, small)
+	o.Order(s, blokus.ColorBlue, 3, moves)
+	if !sameMove(moves[0], small) {
+		t.Errorf("expected the killer move %s first but got %s", small.String(), moves[0].String())
+	}
+	o.Order(s, blokus.ColorBlue, 2, moves)
+	if sameMove(moves[0], small) {
+		t.Errorf("expected the killer move of ply 3 not to be first at ply 2")
+	}
+
+	// among pieces of the same size, the history decides
+	last := len(moves) - 1
+	for PieceSize(moves[last]) != PieceSize(moves[0]) {
+		last--
+	}
+	o.AddHistory(blokus.ColorBlue, moves[last], 2)
+	preferred := moves[last]
+	o.Order(s, blokus.ColorBlue, 2, moves)
+	if !sameMove(moves[0], preferred) {
+		t.Errorf("expected the move with history %s first but got %s", preferred.String(), moves[0].String())
+	}
+	if o.History(blokus.ColorBlue, preferred) != 4 {
+		t.Errorf("expected history 4 but got %d", o.History(blokus.ColorBlue, preferred))
+	}
+	o.AgeHistory()
+	if o.History(blokus.ColorBlue, preferred) != 2 {
+		t.Errorf("expected history 2 after aging but got %d", o.History(blokus.ColorBlue, preferred))
+	}
+	o.Clear()
+	if o.History(blokus.ColorBlue, preferred) != 0 || o.IsKiller(3, small) {
+		t.Errorf("expected Clear to forget history and killers")
+	}
+}
+
+func TestOrderer_NoAllocations(t *testing.T) {
+	s, moves := testPosition()
+	o := NewOrderer()
+	o.AddKiller(1, moves[len(moves)/2])
+	allocs := testing.AllocsPerRun(10, func() {
+		o.Order(s, blokus.ColorBlue, 1, moves)
+		o.AddHistory(blokus.ColorBlue, moves[1], 3)
+		SortBySizeAndDirection(s, blokus.ColorBlue, moves)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations but got %.1f", allocs)
+	}
+}
+
+func BenchmarkOrderer_Order(b *testing.B) {
+	s, moves := testPosition()
+	o := NewOrderer()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		o.Order(s, blokus.ColorBlue, 1, moves)
+	}
+}
@@ -0,0 +1,118 @@
+package moveorder
+
+import (
+	"github.com/hschendel/sc/2021/blokus"
+)
+
+// MaxPly is the number of plies an Orderer keeps killer moves for
+const MaxPly = 64
+
+// historyLimit is the history value at which the whole history table is halved
+const historyLimit = 1 << 16
+
+// Weights of the heuristics combined by Orderer.Score
+type Weights struct {
+	Killer       int64
+	History      int64
+	PieceSize    int64
+	TowardCenter int64
+}
+
+// DefaultWeights try killer moves first, then larger pieces, then the moves with the best history, and then the
+// moves reaching furthest toward the center
+var DefaultWeights = Weights{
+	Killer:       1 << 40,
+	History:      64,
+	PieceSize:    1 << 24,
+	TowardCenter: 1,
+}
+
+// Orderer combines the static heuristics with a history table and killer moves collected during a search. It is not
+// safe for concurrent use, so use one per search.
+type Orderer struct {
+	Weights Weights
+
+	// history contains how often a move of a color caused a cut-off, weighted by depth
+	history [4][256][400]uint32
+	killers [MaxPly][2]blokus.Move
+}
+
+// NewOrderer returns an Orderer with DefaultWeights
+func NewOrderer() *Orderer {
+	return &Orderer{Weights: DefaultWeights}
+}
+
+// Order sorts the moves of c at ply with the highest Score first
+func (o *Orderer) Order(s blokus.State, c blokus.Color, ply int, moves []blokus.Move) {
+	goDown, goRight := blokus.ColorDirection(s, c)
+	sortByKey(moves, func(m blokus.Move) int64 {
+		return o.Score(c, ply, goDown, goRight, m)
+	})
+}
+
+// Score combines the heuristics for the move m of c at ply with Weights. goDown and goRight are the direction of c,
+// see blokus.ColorDirection.
+func (o *Orderer) Score(c blokus.Color, ply int, goDown, goRight bool, m blokus.Move) (score int64) {
+	if o.IsKiller(ply, m) {
+		score += o.Weights.Killer
+	}
+	score += o.Weights.History * int64(o.History(c, m))
+	score += o.Weights.PieceSize * PieceSize(m)
+	score += o.Weights.TowardCenter * TowardCenter(goDown, goRight, m)
+	return
+}
+
+// History returns the history value of the move m of c
+func (o *Orderer) History(c blokus.Color, m blokus.Move) uint32 {
+	if !m.IsMove {
+		return 0
+	}
+	return o.history[c][m.Transformation][int(m.Y)*20+int(m.X)]
+}
+
+// AddHistory records that the move m of c caused a cut-off in a search of the given remaining depth. Deeper
+// searches count more.
+func (o *Orderer) AddHistory(c blokus.Color, m blokus.Move, depth int) {
+	if !m.IsMove {
+		return
+	}
+	h := &o.history[c][m.Transformation][int(m.Y)*20+int(m.X)]
+	*h += uint32(depth * depth)
+	if *h >= historyLimit {
+		o.AgeHistory()
+	}
+}
+
+// AgeHistory halves all history values, so that newer results count more, e.g. between two moves of a game
+func (o *Orderer) AgeHistory() {
+	for c := range o.history {
+		for tp := range o.history[c] {
+			for i := range o.history[c][tp] {
+				o.history[c][tp][i] /= 2
+			}
+		}
+	}
+}
+
+// AddKiller records that m caused a cut-off at ply. The two latest killer moves are kept per ply.
+func (o *Orderer) AddKiller(ply int, m blokus.Move) {
+	if ply < 0 || ply >= MaxPly || sameMove(o.killers[ply][0], m) {
+		return
+	}
+	o.killers[ply][1] = o.killers[ply][0]
+	o.killers[ply][0] = m
+}
+
+// IsKiller returns true if m is one of the killer moves at ply
+func (o *Orderer) IsKiller(ply int, m blokus.Move) bool {
+	if ply < 0 || ply >= MaxPly || !m.IsMove {
+		return false
+	}
+	return sameMove(o.killers[ply][0], m) || sameMove(o.killers[ply][1], m)
+}
+
+// Clear forgets the history and the killer moves, e.g. before a new game
+func (o *Orderer) Clear() {
+	o.history = [4][256][400]uint32{}
+	o.killers = [MaxPly][2]blokus.Move{}
+}
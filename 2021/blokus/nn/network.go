@@ -0,0 +1,271 @@
+// Package nn evaluates small fully connected neural networks on Blokus positions, in plain Go.
+//
+// The input of a network is the position from the view of the color to move c: 4 planes of 20x20 cells, one per
+// color starting with c in the order of play, with input y*20+x of plane i set to 1 if color (c+i)%4 covers x,y,
+// followed by 4 times 21 inputs, set to 1 for the pieces color (c+i)%4 has not played yet. These are the planes and
+// remaining pieces of a dataset.Record, rolled by the color. The output is a single value, e.g. the expected final
+// score difference of the player owning c, as in dataset.Record.
+//
+// A network file consists of little endian numbers: the magic "BLOKUSNN", the format version and the number of layers
+// as uint32, and for every layer its number of inputs and outputs as uint32, its activation as uint8, the weights as
+// inputs x outputs float32 (input major, so the transposed weight of a torch.nn.Linear), and the biases as outputs
+// float32.
+package nn
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/hschendel/sc/2021/blokus"
+	"io"
+	"math"
+	"os"
+)
+
+// InputSize is the number of inputs of a network
+const InputSize = 4*400 + 4*blokus.NumPieces
+
+// FormatVersion is written into every network file
+const FormatVersion = 1
+
+var magic = [8]byte{'B', 'L', 'O', 'K', 'U', 'S', 'N', 'N'}
+
+// ErrNoNetwork is returned by Read if the data does not start like a network file
+var ErrNoNetwork = errors.New("not a network file")
+
+type Activation uint8
+
+const (
+	ActivationLinear = Activation(iota)
+	ActivationReLU
+	ActivationTanh
+)
+
+func (a Activation) String() string {
+	switch a {
+	case ActivationLinear:
+		return "LINEAR"
+	case ActivationReLU:
+		return "RELU"
+	case ActivationTanh:
+		return "TANH"
+	default:
+		return fmt.Sprintf("Activation(%d)", uint8(a))
+	}
+}
+
+// Layer is a fully connected layer
+type Layer struct {
+	Inputs  int
+	Outputs int
+	// Weights contains Inputs x Outputs weights, the weight from input i to output j is at i*Outputs+j
+	Weights    []float32
+	Biases     []float32
+	Activation Activation
+}
+
+// forward calculates the outputs of l for in into out. Inputs that are 0 are skipped, which makes the first layer
+// fast, as only few inputs are set.
+func (l *Layer) forward(in, out []float32) {
+	copy(out, l.Biases)
+	for i, v := range in {
+		if v == 0 {
+			continue
+		}
+		w := l.Weights[i*l.Outputs : (i+1)*l.Outputs]
+		for j := range out {
+			out[j] += v * w[j]
+		}
+	}
+	l.activate(out)
+}
+
+func (l *Layer) activate(out []float32) {
+	switch l.Activation {
+	case ActivationReLU:
+		for j, v := range out {
+			if v < 0 {
+				out[j] = 0
+			}
+		}
+	case ActivationTanh:
+		for j, v := range out {
+			out[j] = float32(math.Tanh(float64(v)))
+		}
+	}
+}
+
+// Network is a sequence of layers with InputSize inputs and a single output. Its methods are not safe for
+// concurrent use, as it reuses its buffers.
+type Network struct {
+	Layers []Layer
+
+	input   []float32
+	active  []int
+	buffers [2][]float32
+}
+
+// Validate returns an error if the layers do not fit together
+func (n *Network) Validate() error {
+	if len(n.Layers) == 0 {
+		return errors.New("network has no layers")
+	}
+	inputs := InputSize
+	for i := range n.Layers {
+		l := &n.Layers[i]
+		if l.Inputs != inputs {
+			return fmt.Errorf("layer %d: expected %d inputs but got %d", i, inputs, l.Inputs)
+		}
+		if l.Outputs <= 0 || len(l.Weights) != l.Inputs*l.Outputs || len(l.Biases) != l.Outputs {
+			return fmt.Errorf("layer %d: invalid number of weights or biases", i)
+		}
+		if l.Activation > ActivationTanh {
+			return fmt.Errorf("layer %d: unknown activation %d", i, l.Activation)
+		}
+		inputs = l.Outputs
+	}
+	if inputs != 1 {
+		return fmt.Errorf("expected 1 output but got %d", inputs)
+	}
+	return nil
+}
+
+// Evaluate returns the output of the network for s from the view of c. Its signature matches eval.Feature.Value, so
+// a network can be used as feature.
+func (n *Network) Evaluate(s blokus.State, c blokus.Color) float64 {
+	if n.input == nil {
+		n.input = make([]float32, InputSize)
+		n.active = make([]int, 0, InputSize)
+	}
+	for _, i := range n.active {
+		n.input[i] = 0
+	}
+	n.active = n.active[:0]
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			if oc, hasPiece := s.At(x, y); hasPiece {
+				n.active = append(n.active, int((oc+4-c)%4)*400+int(y)*20+int(x))
+			}
+		}
+	}
+	for i := blokus.Color(0); i < 4; i++ {
+		for _, p := range s.NotPlayedPiecesFor((c + i) % 4) {
+			n.active = append(n.active, 4*400+int(i)*blokus.NumPieces+int(p))
+		}
+	}
+	for _, i := range n.active {
+		n.input[i] = 1
+	}
+	return float64(n.forward(n.input))
+}
+
+func (n *Network) forward(input []float32) float32 {
+	in := input
+	for i := range n.Layers {
+		l := &n.Layers[i]
+		buf := n.buffers[i%2]
+		if cap(buf) < l.Outputs {
+			buf = make([]float32, l.Outputs)
+			n.buffers[i%2] = buf
+		}
+		out := buf[:l.Outputs]
+		l.forward(in, out)
+		in = out
+	}
+	return in[0]
+}
+
+// Load reads a network file
+func Load(path string) (n *Network, err error) {
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		return
+	}
+	defer f.Close()
+	if n, err = Read(bufio.NewReader(f)); err != nil {
+		err = fmt.Errorf("cannot read network file %s: %s", path, err)
+	}
+	return
+}
+
+// Read reads a network in the format described in the package documentation, and validates it
+func Read(r io.Reader) (n *Network, err error) {
+	var header struct {
+		Magic   [8]byte
+		Version uint32
+		Layers  uint32
+	}
+	if err = binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return
+	}
+	if !bytes.Equal(header.Magic[:], magic[:]) {
+		err = ErrNoNetwork
+		return
+	}
+	if header.Version != FormatVersion {
+		err = fmt.Errorf("unsupported network format version %d", header.Version)
+		return
+	}
+	// a sanity limit, so that broken files do not lead to huge allocations
+	const maxSize = 1 << 24
+	n = new(Network)
+	for i := uint32(0); i < header.Layers; i++ {
+		var lh struct {
+			Inputs     uint32
+			Outputs    uint32
+			Activation Activation
+		}
+		if err = binary.Read(r, binary.LittleEndian, &lh); err != nil {
+			return
+		}
+		if uint64(lh.Inputs)*uint64(lh.Outputs) > maxSize {
+			err = fmt.Errorf("layer %d is too large", i)
+			return
+		}
+		l := Layer{
+			Inputs:     int(lh.Inputs),
+			Outputs:    int(lh.Outputs),
+			Weights:    make([]float32, int(lh.Inputs)*int(lh.Outputs)),
+			Biases:     make([]float32, lh.Outputs),
+			Activation: lh.Activation,
+		}
+		if err = binary.Read(r, binary.LittleEndian, l.Weights); err != nil {
+			return
+		}
+		if err = binary.Read(r, binary.LittleEndian, l.Biases); err != nil {
+			return
+		}
+		n.Layers = append(n.Layers, l)
+	}
+	err = n.Validate()
+	return
+}
+
+// Write writes n in the format described in the package documentation
+func (n *Network) Write(w io.Writer) (err error) {
+	bw := bufio.NewWriter(w)
+	if err = binary.Write(bw, binary.LittleEndian, magic); err != nil {
+		return
+	}
+	if err = binary.Write(bw, binary.LittleEndian, []uint32{FormatVersion, uint32(len(n.Layers))}); err != nil {
+		return
+	}
+	for i := range n.Layers {
+		l := &n.Layers[i]
+		if err = binary.Write(bw, binary.LittleEndian, []uint32{uint32(l.Inputs), uint32(l.Outputs)}); err != nil {
+			return
+		}
+		if err = bw.WriteByte(uint8(l.Activation)); err != nil {
+			return
+		}
+		if err = binary.Write(bw, binary.LittleEndian, l.Weights); err != nil {
+			return
+		}
+		if err = binary.Write(bw, binary.LittleEndian, l.Biases); err != nil {
+			return
+		}
+	}
+	return bw.Flush()
+}
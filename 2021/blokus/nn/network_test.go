@@ -0,0 +1,153 @@
+package nn
+
+import (
+	"bytes"
+	"github.com/hschendel/sc"
+	"github.com/hschendel/sc/2021/blokus"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// cellCountNetwork returns a network whose output is the number of cells covered by the color it is evaluated for,
+// minus the cells of the next color
+func cellCountNetwork() *Network {
+	hidden := Layer{Inputs: InputSize, Outputs: 2, Weights: make([]float32, InputSize*2), Biases: make([]float32, 2), Activation: ActivationReLU}
+	for i := 0; i < 400; i++ {
+		hidden.Weights[i*2] = 1
+		hidden.Weights[(400+i)*2+1] = 1
+	}
+	output := Layer{Inputs: 2, Outputs: 1, Weights: []float32{1, -1}, Biases: []float32{0}}
+	return &Network{Layers: []Layer{hidden, output}}
+}
+
+// lastMoverNetwork returns a network whose output is minus the number of cells covered by the color before the one
+// it is evaluated for, i.e. the color that has just moved
+func lastMoverNetwork() *Network {
+	hidden := Layer{Inputs: InputSize, Outputs: 1, Weights: make([]float32, InputSize), Biases: make([]float32, 1), Activation: ActivationReLU}
+	for i := 0; i < 400; i++ {
+		hidden.Weights[3*400+i] = 1
+	}
+	output := Layer{Inputs: 1, Outputs: 1, Weights: []float32{-1}, Biases: []float32{0}}
+	return &Network{Layers: []Layer{hidden, output}}
+}
+
+// randomNetwork returns a network with random weights and the given hidden layer sizes
+func randomNetwork(rnd *rand.Rand, hidden ...int) *Network {
+	n := new(Network)
+	inputs := InputSize
+	for _, outputs := range append(hidden, 1) {
+		l := Layer{Inputs: inputs, Outputs: outputs, Weights: make([]float32, inputs*outputs), Biases: make([]float32, outputs), Activation: ActivationReLU}
+		for i := range l.Weights {
+			l.Weights[i] = rnd.Float32() - 0.5
+		}
+		n.Layers = append(n.Layers, l)
+		inputs = outputs
+	}
+	n.Layers[len(n.Layers)-1].Activation = ActivationLinear
+	return n
+}
+
+func testState() blokus.State {
+	g := blokus.NewGame(new(blokus.BasicState), blokus.PiecePentoL)
+	for i := 0; i < 6; i++ {
+		if err := g.Play(g.LegalMoves()[0]); err != nil {
+			panic(err)
+		}
+	}
+	return g.State()
+}
+
+func TestNetwork_Evaluate(t *testing.T) {
+	n := cellCountNetwork()
+	if err := n.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	s := testState()
+	// blue and yellow have played twice, red and green once
+	if v := n.Evaluate(s, blokus.ColorBlue); v != 0 {
+		t.Errorf("expected blue to have as many cells as yellow, but got %f", v)
+	}
+	if v := n.Evaluate(s, blokus.ColorYellow); v != 5 {
+		t.Errorf("expected yellow to have 5 cells more than red, but got %f", v)
+	}
+	if v := n.Evaluate(s, blokus.ColorGreen); v != -5 {
+		t.Errorf("expected green to have 5 cells less than blue, but got %f", v)
+	}
+}
+
+func TestNetwork_WriteRead(t *testing.T) {
+	n := randomNetwork(rand.New(rand.NewSource(1)), 8, 4)
+	var buf bytes.Buffer
+	if err := n.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read.Layers, n.Layers) {
+		t.Errorf("expected the layers read to equal the layers written")
+	}
+	s := testState()
+	if read.Evaluate(s, blokus.ColorRed) != n.Evaluate(s, blokus.ColorRed) {
+		t.Errorf("expected the same output")
+	}
+
+	n.Layers[1].Inputs = 7
+	buf.Reset()
+	if err = n.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Read(&buf); err == nil {
+		t.Errorf("expected an error for layers that do not fit together")
+	}
+	if _, err = Read(bytes.NewReader(make([]byte, 16))); err != ErrNoNetwork {
+		t.Errorf("expected ErrNoNetwork but got %v", err)
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	// after red moved, green rates the position, and its opponent red counts against it
+	p := Player{Network: lastMoverNetwork()}
+	s := testState()
+	m := p.NextMove(s, blokus.ColorRed, sc.NewTimeout(time.Second))
+	if m.IsEmpty() || !blokus.CanApplyMove(s, blokus.ColorRed, m) {
+		t.Fatalf("expected a legal move but got %s", m.String())
+	}
+	if m.Transformation.Piece().NumPoints() != 5 {
+		t.Errorf("expected a pentomino, as it covers the most cells, but got %s", m.String())
+	}
+	// red played one pentomino before
+	if v := p.LastEvaluation()[0].Value; v != 10 {
+		t.Errorf("expected the value to be the 10 cells of red, but got %f", v)
+	}
+}
+
+func TestPlayer_value(t *testing.T) {
+	n := cellCountNetwork()
+	p := Player{Network: n}
+	s := testState()
+	// green moves after red, and belongs to the other player
+	if v, e := p.value(s, blokus.ColorRed), -n.Evaluate(s, blokus.ColorGreen); v != e {
+		t.Errorf("expected %f from the view of green, negated, but got %f", e, v)
+	}
+	// without green, blue moves after red, and belongs to the same player
+	var withoutGreen blokus.BasicState
+	blokus.CopyState(&withoutGreen, s)
+	withoutGreen.SetColorValid(blokus.ColorGreen, false)
+	if v, e := p.value(&withoutGreen, blokus.ColorRed), n.Evaluate(&withoutGreen, blokus.ColorBlue); v != e {
+		t.Errorf("expected %f from the view of blue but got %f", e, v)
+	}
+}
+
+func BenchmarkNetwork_Evaluate(b *testing.B) {
+	n := randomNetwork(rand.New(rand.NewSource(1)), 64, 32)
+	s := testState()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n.Evaluate(s, blokus.ColorBlue)
+	}
+}
@@ -0,0 +1,94 @@
+package nn
+
+import (
+	"github.com/hschendel/sc"
+	"github.com/hschendel/sc/2021/blokus"
+)
+
+// Player plays the move after which Network rates the position best for the player owning the color that moved.
+// Among equally rated moves it picks the first one.
+//
+// Like in a dataset.Record, the network rates a position from the view of the color to move. So the position after
+// a move is rated from the view of the next color that can move, and the value is negated if that color belongs to
+// the other player. If no color can move anymore, the final score difference is used instead.
+type Player struct {
+	Network *Network
+
+	lastEvaluation  []blokus.Evaluation
+	lastSearchStats blokus.SearchStats
+}
+
+// NewPlayer returns a Player with the network loaded from path
+func NewPlayer(path string) (p *Player, err error) {
+	var n *Network
+	if n, err = Load(path); err != nil {
+		return
+	}
+	p = &Player{Network: n}
+	return
+}
+
+func (p *Player) NextMove(state blokus.State, color blokus.Color, timeout sc.Timeout) blokus.Move {
+	moves := blokus.PossibleNextMoves(state, color)
+	if len(moves) == 0 {
+		return blokus.EmptyMove
+	}
+	var s blokus.BasicState
+	blokus.CopyState(&s, state)
+	best := 0
+	var bestValue float64
+	for i, m := range moves {
+		blokus.MustApplyMove(&s, color, m)
+		value := p.value(&s, color)
+		blokus.UndoMove(&s, color, m)
+		if i == 0 || value > bestValue {
+			best, bestValue = i, value
+		}
+	}
+	p.lastEvaluation = []blokus.Evaluation{
+		{Name: "network value", Value: bestValue},
+		{Name: "possible moves", Value: float64(len(moves))},
+	}
+	p.lastSearchStats = blokus.SearchStats{Nodes: uint64(len(moves)), Depth: 1}
+	return moves[best]
+}
+
+// value rates s after c has moved for the player owning c
+func (p *Player) value(s blokus.State, c blokus.Color) float64 {
+	next, found := nextColor(s, c)
+	if !found {
+		return float64(blokus.OfficialRatingForPlayer(s, c%2 == 0)) - float64(blokus.OfficialRatingForPlayer(s, c%2 != 0))
+	}
+	value := p.Network.Evaluate(s, next)
+	if next%2 != c%2 {
+		value = -value
+	}
+	return value
+}
+
+// nextColor returns the color to move after c, in the same way as blokus.Game: the next color that is still in the
+// game and can move
+func nextColor(s blokus.State, c blokus.Color) (next blokus.Color, found bool) {
+	next = c
+	for i := 0; i < 4; i++ {
+		next = (next + 1) % 4
+		if s.IsColorValid(next) && (!s.HasPlayed(next) || blokus.HasPossibleNextMoves(s, next)) {
+			found = true
+			return
+		}
+	}
+	return
+}
+
+func (p *Player) End() {
+}
+
+// LastEvaluation reports the network value of the last move picked, see blokus.EvaluationReporter
+func (p *Player) LastEvaluation() []blokus.Evaluation {
+	return p.lastEvaluation
+}
+
+// LastSearchStats reports the number of moves rated for the last move, see blokus.SearchStatsReporter
+func (p *Player) LastSearchStats() blokus.SearchStats {
+	return p.lastSearchStats
+}
@@ -1,24 +1,46 @@
 package blokus
 
+// OfficialRatingForColor returns the points of c as counted by the official server: one point for every square of
+// the pieces played, plus AdditionalPointsIfAllPiecesPlayed if all pieces were played, plus
+// AdditionalPointsIfLastMoveMono if the last of them was the mono piece.
 func OfficialRatingForColor(s State, c Color) (points uint) {
+	for _, p := range AllPieces {
+		if s.IsPiecePlayed(c, p) {
+			points += p.NumPoints()
+		}
+	}
 	if len(s.NotPlayedPiecesFor(c)) == 0 {
-		points += PointsForAllPieces
+		points += AdditionalPointsIfAllPiecesPlayed
 		if s.IsLastMoveMono(c) {
 			points += AdditionalPointsIfLastMoveMono
 		}
-		return
 	}
-	for _, p := range AllPieces {
-		if s.IsPiecePlayed(c, p) {
-			points += p.NumPoints()
-		}
+	return
+}
+
+// OfficialRatingForPlayer returns the sum of the points of the colors of the first or the second player
+func OfficialRatingForPlayer(s State, isFirstPlayer bool) (points uint) {
+	c := ColorBlue
+	if !isFirstPlayer {
+		c = ColorYellow
+	}
+	for _, oc := range OwnColors(c) {
+		points += OfficialRatingForColor(s, oc)
 	}
 	return
 }
 
-func OfficialRatingForPlayer(s State, isFirstPlayer bool) uint {
-	if isFirstPlayer {
-		return OfficialRatingForColor(s, ColorBlue) + OfficialRatingForColor(s, ColorGreen)
+// OfficialResult returns the points of both players, and who won if the game ended in s
+func OfficialResult(s State) (result GameResult, score1, score2 uint) {
+	score1 = OfficialRatingForPlayer(s, true)
+	score2 = OfficialRatingForPlayer(s, false)
+	switch {
+	case score1 > score2:
+		result = GameResultPlayer1Won
+	case score2 > score1:
+		result = GameResultPlayer2Won
+	default:
+		result = GameResultDraw
 	}
-	return OfficialRatingForColor(s, ColorYellow) + OfficialRatingForColor(s, ColorRed)
+	return
 }
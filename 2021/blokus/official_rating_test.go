@@ -0,0 +1,125 @@
+package blokus
+
+import (
+	"github.com/hschendel/sc"
+	"testing"
+)
+
+// emptyBoard is the board part of a position notation without any pieces. The rating only depends on the pieces
+// played and the mono flags, not on where the pieces are.
+const emptyBoard = "20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20"
+
+func TestOfficialResult(t *testing.T) {
+	cases := []struct {
+		name     string
+		position string
+		score1   uint
+		score2   uint
+		result   GameResult
+	}{
+		{name: "nothing played", position: emptyBoard + " 1fffff,1fffff,1fffff,1fffff - PENTO_L B BYRG 1",
+			score1: 0, score2: 0, result: GameResultDraw},
+		// blue+red own MONO and PENTO_L = 1+5, yellow+green own DOMINO and TRIO_L = 2+3
+		{name: "colors of a player", position: emptyBoard + " 1ffffe,1ffffd,1ffdff,1ffffb - PENTO_L B - 1",
+			score1: 6, score2: 5, result: GameResultPlayer1Won},
+		// blue: 89 points for all pieces + 15 + 5 for the mono last, yellow: 89 + 15
+		{name: "all pieces, mono last", position: emptyBoard + " 0,0,1fffff,1fffff B PENTO_L B - 1",
+			score1: 109, score2: 104, result: GameResultPlayer1Won},
+		// the mono flag only counts if all pieces were played
+		{name: "mono flag without all pieces", position: emptyBoard + " 1,1ffffe,1fffff,1fffff BY PENTO_L B - 1",
+			score1: 88, score2: 1, result: GameResultPlayer1Won},
+		// red and green played all pieces, blue and yellow only the start piece PENTO_X
+		{name: "second and fourth color", position: emptyBoard + " 17ffff,17ffff,0,0 G PENTO_X B - 1",
+			score1: 109, score2: 114, result: GameResultPlayer2Won},
+	}
+	for _, tc := range cases {
+		s := MustParsePosition(tc.position)
+		result, score1, score2 := OfficialResult(s)
+		if result != tc.result || score1 != tc.score1 || score2 != tc.score2 {
+			t.Errorf("%s: expected %s with %d:%d but got %s with %d:%d", tc.name, tc.result, tc.score1, tc.score2, result, score1, score2)
+		}
+		if o := OfficialRatingForPlayer(s, true); o != score1 {
+			t.Errorf("%s: expected OfficialRatingForPlayer %d for player 1 but got %d", tc.name, score1, o)
+		}
+		if o := OfficialRatingForPlayer(s, false); o != score2 {
+			t.Errorf("%s: expected OfficialRatingForPlayer %d for player 2 but got %d", tc.name, score2, o)
+		}
+	}
+}
+
+func TestApplyMove_LastMoveMono(t *testing.T) {
+	// blue has only the mono and the domino left, at the top left corner of its pieces
+	s := MustParsePosition("B19/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20 3,1fffff,1fffff,1fffff - MONO B BYRG 1")
+	mono := MustParseMove("MONO:NONE@1,1")
+	domino := MustParseMove("DOMINO:NONE@2,2")
+	MustApplyMove(s, ColorBlue, mono)
+	if s.IsLastMoveMono(ColorBlue) {
+		t.Errorf("expected no mono flag while pieces are left")
+	}
+	MustApplyMove(s, ColorBlue, domino)
+	if s.IsLastMoveMono(ColorBlue) {
+		t.Errorf("expected no mono flag after the domino was played last")
+	}
+	UndoMove(s, ColorBlue, domino)
+	UndoMove(s, ColorBlue, mono)
+	MustApplyMove(s, ColorBlue, MustParseMove("DOMINO:NONE@1,1"))
+	MustApplyMove(s, ColorBlue, MustParseMove("MONO:NONE@3,2"))
+	if !s.IsLastMoveMono(ColorBlue) {
+		t.Errorf("expected the mono flag after the mono was played last")
+	}
+	if o := OfficialRatingForColor(s, ColorBlue); o != PointsForAllPieces+AdditionalPointsIfLastMoveMono {
+		t.Errorf("expected %d points but got %d", PointsForAllPieces+AdditionalPointsIfLastMoveMono, o)
+	}
+	UndoMove(s, ColorBlue, MustParseMove("MONO:NONE@3,2"))
+	if s.IsLastMoveMono(ColorBlue) {
+		t.Errorf("expected no mono flag after the mono was taken back")
+	}
+}
+
+// TestMatchEndRules checks that a skipping color is out of the game, and that the result of a match is the
+// official result of its final position
+func TestMatchEndRules(t *testing.T) {
+	var record GameRecord
+	m := Match{Player1: firstMovePlayer{}, Player2: skippingPlayer{}, Seed: 11, Record: &record}
+	result, score1, score2, err1, err2 := m.Run()
+	if err1 != nil || err2 != nil {
+		t.Fatalf("unexpected errors: %v, %v", err1, err2)
+	}
+	skips := 0
+	for _, e := range record.Entries {
+		if e.Move.IsEmpty() {
+			skips++
+			if e.Color%2 != 1 {
+				t.Errorf("unexpected skip of %s", e.Color)
+			}
+		}
+	}
+	if skips != 2 {
+		t.Errorf("expected yellow and green to skip once each, but got %d skips", skips)
+	}
+	states, err := Replay(&record)
+	if err != nil {
+		t.Fatalf("cannot replay: %s", err)
+	}
+	final := states[len(states)-1]
+	eResult, eScore1, eScore2 := OfficialResult(final)
+	if result != eResult || score1 != eScore1 || score2 != eScore2 {
+		t.Errorf("expected %s with %d:%d but got %s with %d:%d", eResult, eScore1, eScore2, result, score1, score2)
+	}
+	if score2 != 2*m.StartPiece().NumPoints() || result != GameResultPlayer1Won {
+		t.Errorf("expected player 2 to only score the start pieces, but got %d", score2)
+	}
+}
+
+// skippingPlayer places the start pieces, and then skips
+type skippingPlayer struct{}
+
+func (s skippingPlayer) NextMove(state State, color Color, timeout sc.Timeout) Move {
+	if !state.HasPlayed(color) {
+		return firstMovePlayer{}.NextMove(state, color, timeout)
+	}
+	return EmptyMove
+}
+
+func (s skippingPlayer) End() {
+}
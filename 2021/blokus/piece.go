@@ -417,6 +417,8 @@ func (p Piece) NumPoints() uint {
 
 const AdditionalPointsIfLastMoveMono = 5
 const AdditionalPointsIfAllPiecesPlayed = 15
+
+// PointsForAllPieces are the points of a color that played all pieces, including AdditionalPointsIfAllPiecesPlayed
 const PointsForAllPieces = 1 + 2 + 2*3 + 5*4 + 12*5 + AdditionalPointsIfAllPiecesPlayed
 
 var AllPieces = [NumPieces]Piece{
@@ -443,6 +445,22 @@ var AllPieces = [NumPieces]Piece{
 	PieceMono,
 }
 
+// StartPieces contains the pieces the server chooses the start piece from: all pentominos but PiecePentoX,
+// which cannot cover a corner.
+var StartPieces = []Piece{
+	PiecePentoL,
+	PiecePentoT,
+	PiecePentoV,
+	PiecePentoS,
+	PiecePentoZ,
+	PiecePentoI,
+	PiecePentoP,
+	PiecePentoW,
+	PiecePentoU,
+	PiecePentoR,
+	PiecePentoY,
+}
+
 func applyTransformation(positions []Position, rotation Rotation, flipped bool) []Position {
 	switch rotation {
 	case RotationRight:
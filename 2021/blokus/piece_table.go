@@ -0,0 +1,138 @@
+package blokus
+
+import (
+	"sync"
+)
+
+// Offset is a position relative to the top left corner of a piece, that can be outside of the piece's box
+type Offset struct {
+	X int8
+	Y int8
+}
+
+// Shape contains precomputed data about a TransformedPiece. All positions are relative to the top left corner of the
+// box of the piece. Shapes are shared, so they must not be modified.
+type Shape struct {
+	// Canonical is the transformation of Piece.Transformations() that covers the same cells
+	Canonical TransformedPiece
+	Width     uint8
+	Height    uint8
+	// Cells are the cells covered, the same as TransformedPiece.Positions
+	Cells []Position
+	// Mask has bit y*5+x set for every cell x,y covered
+	Mask uint32
+	// Corners are the cells that can cover an anchor: cells with a diagonal neighbor outside of the piece that is not
+	// next to any cell of the piece
+	Corners []Position
+	// EdgeNeighbors are the cells outside of the piece next to a cell of the piece, that must not have the color of
+	// the piece
+	EdgeNeighbors []Offset
+}
+
+// MaskBit returns the bit of Shape.Mask for x,y. It is 0 if x,y is outside of the 5x5 box every piece fits into.
+func MaskBit(x, y uint8) uint32 {
+	if x >= 5 || y >= 5 {
+		return 0
+	}
+	return 1 << (y*5 + x)
+}
+
+var pieceTablesOnce sync.Once
+
+// shapes contains the Shape of every TransformedPiece value of a valid piece
+var shapes [256]Shape
+
+// placements contains the placements covering a cell with a corner, per cell index y*20+x, sorted by piece
+var placements [400][]Move
+
+// piecePlacements contains the parts of placements per piece
+var piecePlacements [400][NumPieces][]Move
+
+func initPieceTables() {
+	for p := PieceMono; p < NumPieces; p++ {
+		for rotation := RotationNone; rotation < 4; rotation++ {
+			for _, flipped := range []bool{false, true} {
+				tp := NewTransformedPiece(p, rotation, flipped)
+				shapes[tp] = newShape(tp)
+			}
+		}
+	}
+	for i := range placements {
+		x, y := uint8(i%20), uint8(i/20)
+		var starts [NumPieces + 1]int
+		for p := PieceMono; p < NumPieces; p++ {
+			starts[p] = len(placements[i])
+			for _, tp := range p.Transformations() {
+				for _, corner := range shapes[tp].Corners {
+					if corner.X > x || corner.Y > y {
+						continue
+					}
+					if mx, my := x-corner.X, y-corner.Y; IsOnBoard(tp, mx, my) {
+						placements[i] = append(placements[i], NewMove(tp, mx, my))
+					}
+				}
+			}
+		}
+		starts[NumPieces] = len(placements[i])
+		for p := PieceMono; p < NumPieces; p++ {
+			piecePlacements[i][p] = placements[i][starts[p]:starts[p+1]:starts[p+1]]
+		}
+	}
+}
+
+func newShape(tp TransformedPiece) (s Shape) {
+	s.Width, s.Height = tp.Width(), tp.Height()
+	s.Cells = tp.Positions()
+	for _, utp := range uniquePieceTransformations[tp.Piece()] {
+		if PositionsEqual(s.Cells, utp.Positions()) {
+			s.Canonical = utp
+			break
+		}
+	}
+	for _, c := range s.Cells {
+		s.Mask |= MaskBit(c.X, c.Y)
+	}
+	covers := func(x, y int8) bool {
+		return x >= 0 && y >= 0 && s.Mask&MaskBit(uint8(x), uint8(y)) != 0
+	}
+	nextToPiece := func(x, y int8) bool {
+		return covers(x-1, y) || covers(x+1, y) || covers(x, y-1) || covers(x, y+1)
+	}
+	for _, c := range s.Cells {
+		x, y := int8(c.X), int8(c.Y)
+		for _, d := range []Offset{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+			if nx, ny := x+d.X, y+d.Y; !covers(nx, ny) && !nextToPiece(nx, ny) {
+				s.Corners = append(s.Corners, c)
+				break
+			}
+		}
+	}
+	for y := int8(-1); y <= int8(s.Height); y++ {
+		for x := int8(-1); x <= int8(s.Width); x++ {
+			if !covers(x, y) && nextToPiece(x, y) {
+				s.EdgeNeighbors = append(s.EdgeNeighbors, Offset{X: x, Y: y})
+			}
+		}
+	}
+	return
+}
+
+// ShapeOf returns the precomputed Shape of tp
+func ShapeOf(tp TransformedPiece) *Shape {
+	pieceTablesOnce.Do(initPieceTables)
+	return &shapes[tp]
+}
+
+// PlacementsAt returns all placements of the transformations of Piece.Transformations() on the board that cover x,y
+// with one of their corners, sorted by piece. Every move covering the anchor x,y is one of them. The result is
+// shared, so it must not be modified.
+func PlacementsAt(x, y uint8) []Move {
+	pieceTablesOnce.Do(initPieceTables)
+	return placements[int(y)*20+int(x)]
+}
+
+// PiecePlacementsAt returns the placements of PlacementsAt for piece p
+func PiecePlacementsAt(p Piece, x, y uint8) []Move {
+	pieceTablesOnce.Do(initPieceTables)
+	return piecePlacements[int(y)*20+int(x)][p]
+}
@@ -0,0 +1,104 @@
+package blokus
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestShapeOf(t *testing.T) {
+	for p := PieceMono; p < NumPieces; p++ {
+		for rotation := RotationNone; rotation < 4; rotation++ {
+			for _, flipped := range []bool{false, true} {
+				tp := NewTransformedPiece(p, rotation, flipped)
+				s := ShapeOf(tp)
+				if bits.OnesCount32(s.Mask) != int(p.NumPoints()) {
+					t.Errorf("%s: expected %d bits in mask but got %032b", tp.String(), p.NumPoints(), s.Mask)
+				}
+				for y := uint8(0); y < 7; y++ {
+					for x := uint8(0); x < 7; x++ {
+						expected := false
+						for _, pos := range tp.Positions() {
+							expected = expected || pos == Position{X: x, Y: y}
+						}
+						if tp.Fills(x, y) != expected {
+							t.Errorf("%s: expected Fills(%d, %d) to be %v", tp.String(), x, y, expected)
+						}
+					}
+				}
+				if !PositionsEqual(s.Canonical.Positions(), tp.Positions()) {
+					t.Errorf("%s: expected canonical transformation %s to cover the same cells", tp.String(), s.Canonical.String())
+				}
+			}
+		}
+	}
+}
+
+func TestShapeOf_CornersAndNeighbors(t *testing.T) {
+	cases := []struct {
+		p             Piece
+		corners       int
+		edgeNeighbors int
+	}{
+		{PieceMono, 1, 4},
+		{PieceDomino, 2, 6},
+		{PieceTetroO, 4, 8},
+		{PiecePentoX, 4, 8},
+		{PiecePentoI, 2, 12},
+	}
+	for _, tc := range cases {
+		s := ShapeOf(tc.p.Transformations()[0])
+		if len(s.Corners) != tc.corners || len(s.EdgeNeighbors) != tc.edgeNeighbors {
+			t.Errorf("%s: expected %d corners and %d edge neighbors, but got %v and %v", tc.p.String(), tc.corners, tc.edgeNeighbors, s.Corners, s.EdgeNeighbors)
+		}
+	}
+}
+
+// TestPlacementsAt checks that the placements at the anchors of a color contain all of its possible moves
+func TestPlacementsAt(t *testing.T) {
+	s := earlyTestState()
+	for c := Color(0); c < 4; c++ {
+		var moves []Move
+		seen := make(map[Move]bool)
+		for _, a := range Anchors(s, c) {
+			for _, p := range s.NotPlayedPiecesFor(c) {
+				for _, m := range PiecePlacementsAt(p, a.X, a.Y) {
+					if !seen[m] && CanApplyMove(s, c, m) {
+						seen[m] = true
+						moves = append(moves, m)
+					}
+				}
+			}
+		}
+		if expected := PossibleNextMoves(s, c); !MovesEqual(moves, expected) {
+			t.Errorf("%s: expected %d moves but got %d", c.String(), len(expected), len(moves))
+		}
+	}
+	all := PlacementsAt(10, 10)
+	var n int
+	for p := PieceMono; p < NumPieces; p++ {
+		n += len(PiecePlacementsAt(p, 10, 10))
+	}
+	if n != len(all) {
+		t.Errorf("expected the placements per piece to add up to %d, but got %d", len(all), n)
+	}
+	for _, m := range PlacementsAt(0, 0) {
+		if m.X != 0 || m.Y != 0 || !m.Transformation.Fills(0, 0) {
+			t.Errorf("expected %s to cover the corner", m.String())
+		}
+	}
+	for p := PieceMono; p < NumPieces; p++ {
+		// the X pentomino has no corner cell
+		if n := len(PiecePlacementsAt(p, 0, 0)); (n == 0) != (p == PiecePentoX) {
+			t.Errorf("unexpected %d placements of %s in the corner", n, p.String())
+		}
+	}
+}
+
+var benchmarkFillsResult bool
+
+func BenchmarkTransformedPiece_Fills(b *testing.B) {
+	tp := NewTransformedPiece(PiecePentoW, RotationRight, true)
+	for n := 0; n < b.N; n++ {
+		benchmarkFillsResult = tp.Fills(uint8(n%5), uint8(n/5%5))
+	}
+}
@@ -8,6 +8,45 @@ import (
 type Player interface {
 	// NextMove must return the player's next move before timeout.Reached() == true
 	NextMove(state State, color Color, timeout sc.Timeout) Move
-	// End is called when the game has ended, so the player can stop any ongoing calculations.
+	// End is called when the game has ended, so the player can stop any ongoing calculations. It can be called
+	// while NextMove is still running, e.g. after Match gave up waiting for the move, so it must be safe for
+	// concurrent use with NextMove.
 	End()
 }
+
+// InteractivePlayer is a Player controlled by a human, like HumanPlayer. In local games run by Match, it is not
+// subject to the move timeout, and it may take back its moves.
+type InteractivePlayer interface {
+	Player
+	// UndoRequested is called after NextMove has returned. If it is true, the move returned is discarded, the game is
+	// set back to the state before the player's previous move, and NextMove is called again.
+	UndoRequested() bool
+}
+
+// EvaluationReporter can be implemented by a Player to report the numbers behind its last move, e.g. the rating of
+// the chosen move. Match stores them in the GameRecordEntry of the move.
+type EvaluationReporter interface {
+	// LastEvaluation is called after NextMove has returned
+	LastEvaluation() []Evaluation
+}
+
+// Evaluation is a named number reported by an EvaluationReporter
+type Evaluation struct {
+	Name  string
+	Value float64
+}
+
+// SearchStatsReporter can be implemented by a Player to report how much it searched for its last move. Match stores
+// the stats in the GameRecordEntry of the move, and MatchSeries sums them up per player.
+type SearchStatsReporter interface {
+	// LastSearchStats is called after NextMove has returned
+	LastSearchStats() SearchStats
+}
+
+// SearchStats describes the search for a move
+type SearchStats struct {
+	// Nodes is the number of positions evaluated
+	Nodes uint64
+	// Depth is the search depth reached, in moves
+	Depth uint
+}
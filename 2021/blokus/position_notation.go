@@ -0,0 +1,219 @@
+package blokus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FormatPosition returns a single line describing s completely, so that ParsePosition can restore it. The
+// notation consists of seven fields separated by spaces:
+//
+//  1. the board, with the rows from y=0 to y=19 separated by '/'. Each row lists the cells from x=0 to x=19, using
+//     the color letters B, Y, R and G for filled cells, and the number of consecutive empty cells otherwise
+//  2. the not played pieces of blue, yellow, red and green, separated by ',', each as a hexadecimal bit mask with
+//     bit i set if Piece(i) was not played yet
+//  3. the letters of the colors whose last move was the mono piece, or '-'
+//  4. the start piece
+//  5. the letter of the current color
+//  6. the letters of the valid colors, or '-'
+//  7. 1 if the first player is the first to move, otherwise 2
+//
+// The initial position of a game with start piece PENTO_L is:
+//
+//	20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20 1fffff,1fffff,1fffff,1fffff - PENTO_L B BYRG 1
+func FormatPosition(s State) string {
+	var sb strings.Builder
+	for y := uint8(0); y < 20; y++ {
+		if y > 0 {
+			sb.WriteByte('/')
+		}
+		empty := 0
+		for x := uint8(0); x < 20; x++ {
+			c, hasPiece := s.At(x, y)
+			if !hasPiece {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				sb.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			sb.WriteByte(c.Letter())
+		}
+		if empty > 0 {
+			sb.WriteString(strconv.Itoa(empty))
+		}
+	}
+	sb.WriteByte(' ')
+	for c := Color(0); c < 4; c++ {
+		if c > 0 {
+			sb.WriteByte(',')
+		}
+		var mask uint32
+		for _, p := range s.NotPlayedPiecesFor(c) {
+			mask |= 1 << p
+		}
+		sb.WriteString(strconv.FormatUint(uint64(mask), 16))
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(colorLetters(s.IsLastMoveMono))
+	sb.WriteByte(' ')
+	sb.WriteString(s.StartPiece().String())
+	sb.WriteByte(' ')
+	sb.WriteByte(s.CurrentColor().Letter())
+	sb.WriteByte(' ')
+	sb.WriteString(colorLetters(s.IsColorValid))
+	if s.IsPlayerOneFirst() {
+		sb.WriteString(" 1")
+	} else {
+		sb.WriteString(" 2")
+	}
+	return sb.String()
+}
+
+// colorLetters returns the letters of all colors for which f is true, or "-" if there are none
+func colorLetters(f func(c Color) bool) string {
+	letters := make([]byte, 0, 4)
+	for c := Color(0); c < 4; c++ {
+		if f(c) {
+			letters = append(letters, c.Letter())
+		}
+	}
+	if len(letters) == 0 {
+		return "-"
+	}
+	return string(letters)
+}
+
+// ParsePosition resets s and sets it to the position described by str in the notation of FormatPosition
+func ParsePosition(str string, s MutableState) (err error) {
+	fields := strings.Fields(str)
+	if len(fields) != 7 {
+		return fmt.Errorf("expected 7 fields in position but got %d", len(fields))
+	}
+	s.Reset()
+	if err = parsePositionBoard(fields[0], s); err != nil {
+		return
+	}
+
+	masks := strings.Split(fields[1], ",")
+	if len(masks) != 4 {
+		return fmt.Errorf("expected 4 piece masks but got %d", len(masks))
+	}
+	for c := Color(0); c < 4; c++ {
+		var mask uint64
+		if mask, err = strconv.ParseUint(masks[c], 16, 32); err != nil || mask >= 1<<NumPieces {
+			return fmt.Errorf("invalid piece mask %q for %s", masks[c], c.String())
+		}
+		pieces := make([]Piece, 0, NumPieces)
+		for p := PieceMono; p < NumPieces; p++ {
+			if mask&(1<<p) != 0 {
+				pieces = append(pieces, p)
+			}
+		}
+		s.SetNotPlayedPiecesFor(c, pieces)
+	}
+
+	var lastMoveMono, valid [4]bool
+	if lastMoveMono, err = parseColorLetters(fields[2]); err != nil {
+		return fmt.Errorf("invalid last move mono colors: %s", err)
+	}
+	var startPiece Piece
+	if startPiece, err = ParsePiece(fields[3]); err != nil {
+		return
+	}
+	s.SetStartPiece(startPiece)
+	var current Color
+	if current, err = parseColorLetter(fields[4]); err != nil {
+		return fmt.Errorf("invalid current color: %s", err)
+	}
+	s.SetCurrentColor(current)
+	if valid, err = parseColorLetters(fields[5]); err != nil {
+		return fmt.Errorf("invalid valid colors: %s", err)
+	}
+	for c := Color(0); c < 4; c++ {
+		s.SetLastMoveMono(c, lastMoveMono[c])
+		s.SetColorValid(c, valid[c])
+	}
+	switch fields[6] {
+	case "1":
+		s.SetPlayerOneFirst(true)
+	case "2":
+		s.SetPlayerOneFirst(false)
+	default:
+		return fmt.Errorf("expected 1 or 2 as first player but got %q", fields[6])
+	}
+	return
+}
+
+// MustParsePosition returns the position described by str in a new BasicState, and panics if str cannot be parsed.
+// It is meant for tests.
+func MustParsePosition(str string) *BasicState {
+	s := new(BasicState)
+	if err := ParsePosition(str, s); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func parsePositionBoard(board string, s MutableState) error {
+	rows := strings.Split(board, "/")
+	if len(rows) != 20 {
+		return fmt.Errorf("expected 20 rows but got %d", len(rows))
+	}
+	for y, row := range rows {
+		x := 0
+		for i := 0; i < len(row); i++ {
+			if row[i] >= '0' && row[i] <= '9' {
+				j := i + 1
+				for j < len(row) && row[j] >= '0' && row[j] <= '9' {
+					j++
+				}
+				empty, err := strconv.Atoi(row[i:j])
+				if err != nil || empty > 20 {
+					return fmt.Errorf("row %d: invalid number of empty cells %q", y, row[i:j])
+				}
+				x += empty
+				i = j - 1
+				continue
+			}
+			c, err := parseColorLetter(row[i : i+1])
+			if err != nil {
+				return fmt.Errorf("row %d: %s", y, err)
+			}
+			if x < 20 {
+				s.Set(uint8(x), uint8(y), c, true)
+			}
+			x++
+		}
+		if x != 20 {
+			return fmt.Errorf("row %d has %d instead of 20 cells", y, x)
+		}
+	}
+	return nil
+}
+
+func parseColorLetter(letter string) (c Color, err error) {
+	for c = Color(0); c < 4; c++ {
+		if len(letter) == 1 && c.Letter() == letter[0] {
+			return
+		}
+	}
+	err = fmt.Errorf("unknown color letter %q", letter)
+	return
+}
+
+func parseColorLetters(letters string) (colors [4]bool, err error) {
+	if letters == "-" {
+		return
+	}
+	for i := range letters {
+		var c Color
+		if c, err = parseColorLetter(letters[i : i+1]); err != nil {
+			return
+		}
+		colors[c] = true
+	}
+	return
+}
@@ -0,0 +1,106 @@
+package blokus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatPosition(t *testing.T) {
+	var s BasicState
+	s.Reset()
+	s.SetStartPiece(PiecePentoL)
+	e := "20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20 1fffff,1fffff,1fffff,1fffff - PENTO_L B BYRG 1"
+	if o := FormatPosition(&s); o != e {
+		t.Errorf("expected\n%s\nbut got\n%s", e, o)
+	}
+
+	s.Set(0, 0, ColorBlue, true)
+	s.Set(19, 0, ColorYellow, true)
+	s.Set(3, 19, ColorRed, true)
+	s.Set(4, 19, ColorGreen, true)
+	s.SetNotPlayedPiecesFor(ColorRed, []Piece{PieceMono, PieceDomino})
+	s.SetLastMoveMono(ColorYellow, true)
+	s.SetCurrentColor(ColorGreen)
+	s.SetColorValid(ColorBlue, false)
+	s.SetPlayerOneFirst(false)
+	e = "B18Y/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/3RG15 1fffff,1fffff,3,1fffff Y PENTO_L G YRG 2"
+	if o := FormatPosition(&s); o != e {
+		t.Errorf("expected\n%s\nbut got\n%s", e, o)
+	}
+}
+
+// TestParsePosition_Examples shows the notation of some positions that are set up by hand
+func TestParsePosition_Examples(t *testing.T) {
+	var s0 BasicState
+	s0.Reset()
+	s0.SetStartPiece(PieceMono)
+	s0.Set(19, 19, ColorRed, true)
+	s0.SetNotPlayedPiecesFor(ColorRed, []Piece{PieceTetroO})
+	var s1 BasicState
+	s1.Reset()
+	s1.SetStartPiece(PieceMono)
+	s1.Set(0, 19, ColorRed, true)
+	s1.Set(1, 18, ColorRed, true)
+	s1.Set(0, 17, ColorGreen, true)
+	s1.SetNotPlayedPiecesFor(ColorRed, []Piece{PieceMono})
+	var cases = []struct {
+		str string
+		s   State
+	}{
+		{str: "20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/19R 1fffff,1fffff,10,1fffff - MONO B BYRG 1", s: &s0},
+		{str: "20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/20/G19/1R18/R19 1fffff,1fffff,1,1fffff - MONO B BYRG 1", s: &s1},
+	}
+	for i, tc := range cases {
+		if o := FormatPosition(tc.s); o != tc.str {
+			t.Errorf("case %d: expected\n%s\nbut got\n%s", i, tc.str, o)
+		}
+		if o := FormatPosition(MustParsePosition(tc.str)); o != tc.str {
+			t.Errorf("case %d: expected\n%s\nbut got\n%s", i, tc.str, o)
+		}
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	var record GameRecord
+	m := Match{Player1: firstMovePlayer{}, Player2: firstMovePlayer{}, Seed: 5, Record: &record}
+	m.Run()
+	states, err := Replay(&record)
+	if err != nil {
+		t.Fatalf("cannot replay: %s", err)
+	}
+	for i, s := range states {
+		str := FormatPosition(s)
+		var o BasicState
+		if err := ParsePosition(str, &o); err != nil {
+			t.Fatalf("state %d: cannot parse %q: %s", i, str, err)
+		}
+		if FormatPosition(&o) != str {
+			t.Errorf("state %d: expected\n%s\nbut got\n%s", i, str, FormatPosition(&o))
+		}
+		if FormatBoard(&o, BoardFormat{}) != FormatBoard(s, BoardFormat{}) {
+			t.Errorf("state %d: board differs", i)
+		}
+	}
+
+	initial := FormatPosition(states[0])
+	invalid := []string{
+		"",
+		strings.Replace(initial, " 1", "", 1),
+		strings.Replace(initial, "20/", "19/", 1),
+		strings.Replace(initial, "20/", "21/", 1),
+		strings.Replace(initial, "20/", "X19/", 1),
+		strings.Replace(initial, "20/", "", 1),
+		strings.Replace(initial, "1fffff,", "", 1),
+		strings.Replace(initial, "1fffff", "3fffff", 1),
+		strings.Replace(initial, " - ", " X ", 1),
+		strings.Replace(initial, " B ", " BY ", 1),
+		strings.Replace(initial, " BYRG ", " BYRGX ", 1),
+		strings.Replace(initial, " 1", " 3", 1),
+	}
+	for _, str := range invalid {
+		var o BasicState
+		if err := ParsePosition(str, &o); err == nil {
+			t.Errorf("expected error for %q", str)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package protocol
+
+// ResultDefinition describes the score fragments of a Result
+type ResultDefinition struct {
+	Fragments []ResultFragment `xml:"fragment"`
+}
+
+type ResultFragment struct {
+	Name               string `xml:"name,attr"`
+	Aggregation        string `xml:"aggregation"`
+	RelevantForRanking bool   `xml:"relevantForRanking"`
+}
+
+// ResultEntry is the score of one player in a Result
+type ResultEntry struct {
+	Player ResultPlayer `xml:"player"`
+	Score  Score        `xml:"score"`
+}
+
+type ResultPlayer struct {
+	Name  string    `xml:"name,attr"`
+	Color TeamColor `xml:"color"`
+}
+
+type Score struct {
+	Cause  string `xml:"cause,attr"`
+	Reason string `xml:"reason,attr"`
+	Parts  []uint `xml:"part"`
+}
+
+type Winner struct {
+	DisplayName string    `xml:"displayName,attr"`
+	Color       TeamColor `xml:"color"`
+}
+
+const ScoreCauseRegular = "REGULAR"
+const ScoreCauseRuleViolation = "RULE_VIOLATION"
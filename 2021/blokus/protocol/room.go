@@ -14,6 +14,10 @@ type Data struct {
 	ColorField string `xml:"color,omitempty"`
 	Piece      *Piece `xml:"piece,omitempty"`
 	State      *State `xml:"state,omitempty"`
+	// Definition, Entries and Winner are only set for class result
+	Definition *ResultDefinition `xml:"definition,omitempty"`
+	Entries    []ResultEntry     `xml:"entry,omitempty"`
+	Winner     *Winner           `xml:"winner,omitempty"`
 }
 
 const DataClassState = "memento"
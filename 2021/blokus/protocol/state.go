@@ -3,21 +3,26 @@ package protocol
 import "encoding/xml"
 
 type State struct {
-	Turn         uint   `xml:"turn,attr"`
-	Round        uint   `xml:"round,attr"`
-	StartPiece   string `xml:"startPiece,attr"`
-	StartTeam    StartTeam
-	BlueShapes   []string     `xml:"blueShapes>shape"`
-	YellowShapes []string     `xml:"yellowShapes>shape"`
-	RedShapes    []string     `xml:"redShapes>shape"`
-	GreenShapes  []string     `xml:"greenShapes>shape"`
-	ValidColors  []string     `xml:"validColors>color"`
-	FirstTeam    Team         `xml:"first"`
-	SecondTeam   Team         `xml:"second"`
-	Board        []Field      `xml:"board>field"`
-	LastMoveMono []ColorEntry `xml:"lastMoveMono>entry,omitempty"`
+	Class             string `xml:"class,attr,omitempty"`
+	CurrentColorIndex uint   `xml:"currentColorIndex,attr"`
+	Turn              uint   `xml:"turn,attr"`
+	Round             uint   `xml:"round,attr"`
+	StartPiece        string `xml:"startPiece,attr"`
+	StartTeam         StartTeam
+	BlueShapes        []string     `xml:"blueShapes>shape"`
+	YellowShapes      []string     `xml:"yellowShapes>shape"`
+	RedShapes         []string     `xml:"redShapes>shape"`
+	GreenShapes       []string     `xml:"greenShapes>shape"`
+	ValidColors       []string     `xml:"validColors>color"`
+	FirstTeam         Team         `xml:"first"`
+	SecondTeam        Team         `xml:"second"`
+	Board             []Field      `xml:"board>field"`
+	LastMoveMono      []ColorEntry `xml:"lastMoveMono>entry,omitempty"`
+	LastMove          *LastMove    `xml:"lastMove,omitempty"`
 }
 
+const StateClass = "state"
+
 type StartTeam struct {
 	XMLName xml.Name `xml:"startTeam"`
 	Class   string   `xml:"class,attr"`
@@ -25,8 +30,24 @@ type StartTeam struct {
 }
 
 type Team struct {
-	DisplayName string `xml:"displayName,attr"`
-	Color       string `xml:"color"`
+	DisplayName string    `xml:"displayName,attr"`
+	Color       TeamColor `xml:"color"`
+}
+
+type TeamColor struct {
+	Class string `xml:"class,attr,omitempty"`
+	Name  string `xml:",chardata"`
+}
+
+const TeamClass = "team"
+const TeamOne = "ONE"
+const TeamTwo = "TWO"
+
+// LastMove is the last move in a State. Piece is nil for a skip move.
+type LastMove struct {
+	Class string `xml:"class,attr"`
+	Color string `xml:"color,omitempty"`
+	Piece *Piece `xml:"piece,omitempty"`
 }
 
 type Field struct {
@@ -34,3 +34,14 @@ func RandomInt(max int) int {
 	}
 	return int(bn.Int64())
 }
+
+// RandomSeed returns a random seed, e.g. for Match.Seed
+func RandomSeed() int64 {
+	var bmax big.Int
+	bmax.SetUint64(1 << 63)
+	bn, err := rand.Int(rand.Reader, &bmax)
+	if err != nil {
+		panic(fmt.Errorf("cannot read from random device: %s", err))
+	}
+	return bn.Int64()
+}
@@ -0,0 +1,236 @@
+// Package render draws Blokus boards as SVG or raster images, using only the standard library.
+package render
+
+import (
+	"fmt"
+	"github.com/hschendel/sc/2021/blokus"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+)
+
+// Options controls how a State is drawn
+type Options struct {
+	// CellSize is the width and height of a board cell in pixels. If it is 0, DefaultCellSize is used.
+	CellSize int
+	// LastMove is outlined on the board, if it is not empty
+	LastMove blokus.Move
+	// ShowRemaining draws the pieces that have not been played yet next to the board, one block per color
+	ShowRemaining bool
+}
+
+const DefaultCellSize = 24
+
+var (
+	ColorBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	ColorEmpty      = color.RGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+	ColorGrid       = color.RGBA{R: 0xc8, G: 0xc8, B: 0xc8, A: 0xff}
+	ColorHighlight  = color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0xff}
+)
+
+// PieceColors contains the fill color for each blokus.Color
+var PieceColors = [4]color.RGBA{
+	blokus.ColorBlue:   {R: 0x1e, G: 0x64, B: 0xdc, A: 0xff},
+	blokus.ColorYellow: {R: 0xf0, G: 0xc8, B: 0x14, A: 0xff},
+	blokus.ColorRed:    {R: 0xdc, G: 0x28, B: 0x28, A: 0xff},
+	blokus.ColorGreen:  {R: 0x28, G: 0xa0, B: 0x3c, A: 0xff},
+}
+
+// shape is a single element of a drawing. If outline is > 0, only the border of the rectangle is drawn,
+// with a width of outline pixels.
+type shape struct {
+	r       image.Rectangle
+	fill    color.RGBA
+	outline int
+}
+
+type drawing struct {
+	size   image.Point
+	shapes []shape
+	// labels are only drawn in SVG output
+	labels []label
+}
+
+type label struct {
+	at   image.Point
+	text string
+}
+
+const margin = 20
+
+// remainingColumns is the width of the remaining pieces panel in small cells
+const remainingColumns = 36
+
+func (o *Options) cellSize() int {
+	if o.CellSize <= 0 {
+		return DefaultCellSize
+	}
+	return o.CellSize
+}
+
+func layout(s blokus.State, o Options) (d drawing) {
+	cs := o.cellSize()
+	boardSize := 20 * cs
+	d.size = image.Pt(2*margin+boardSize, 2*margin+boardSize)
+	d.shapes = append(d.shapes, shape{r: image.Rect(0, 0, d.size.X, d.size.Y), fill: ColorBackground})
+	d.shapes = append(d.shapes, shape{r: image.Rect(margin-1, margin-1, margin+boardSize, margin+boardSize), fill: ColorGrid})
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			fill := ColorEmpty
+			if c, hasPiece := s.At(x, y); hasPiece {
+				fill = PieceColors[c]
+			}
+			d.shapes = append(d.shapes, shape{r: cellRect(margin, margin, cs, int(x), int(y)).Inset(1), fill: fill})
+		}
+	}
+	for i := 0; i < 20; i++ {
+		d.labels = append(d.labels,
+			label{at: image.Pt(margin+i*cs+cs/2, margin-6), text: fmt.Sprint(i)},
+			label{at: image.Pt(margin/2, margin+i*cs+cs/2+4), text: fmt.Sprint(i)})
+	}
+	if !o.LastMove.IsEmpty() {
+		for _, pos := range o.LastMove.Transformation.Positions() {
+			r := cellRect(margin, margin, cs, int(o.LastMove.X+pos.X), int(o.LastMove.Y+pos.Y))
+			d.shapes = append(d.shapes, shape{r: r.Inset(1), fill: ColorHighlight, outline: 2})
+		}
+	}
+	if o.ShowRemaining {
+		layoutRemaining(s, &d, d.size.X, cs)
+	}
+	return
+}
+
+// layoutRemaining adds the not played pieces of every color to the right of the board at x
+func layoutRemaining(s blokus.State, d *drawing, x, cs int) {
+	small := cs / 4
+	if small < 2 {
+		small = 2
+	}
+	y := margin
+	for c := blokus.Color(0); c < 4; c++ {
+		col, rowHeight := 0, 0
+		for _, p := range s.NotPlayedPiecesFor(c) {
+			w, h := int(p.Width()), int(p.Height())
+			if col+w > remainingColumns {
+				col = 0
+				y += (rowHeight + 1) * small
+				rowHeight = 0
+			}
+			for _, pos := range p.Points() {
+				r := cellRect(x+col*small, y, small, int(pos.X), int(pos.Y))
+				d.shapes = append(d.shapes, shape{r: r, fill: PieceColors[c]})
+			}
+			col += w + 1
+			if h > rowHeight {
+				rowHeight = h
+			}
+		}
+		y += (rowHeight + 2) * small
+	}
+	d.size.X = x + remainingColumns*small + margin
+	if y+margin > d.size.Y {
+		d.size.Y = y + margin
+		d.shapes[0].r.Max.Y = d.size.Y
+	}
+	d.shapes[0].r.Max.X = d.size.X
+}
+
+func cellRect(originX, originY, size, x, y int) image.Rectangle {
+	return image.Rect(originX+x*size, originY+y*size, originX+(x+1)*size, originY+(y+1)*size)
+}
+
+// SVG writes the board of s as an SVG image to w
+func SVG(w io.Writer, s blokus.State, o Options) (err error) {
+	d := layout(s, o)
+	if _, err = fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", d.size.X, d.size.Y, d.size.X, d.size.Y); err != nil {
+		return
+	}
+	for _, sh := range d.shapes {
+		if sh.outline > 0 {
+			_, err = fmt.Fprintf(w, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"none\" stroke=\"%s\" stroke-width=\"%d\"/>\n", sh.r.Min.X, sh.r.Min.Y, sh.r.Dx(), sh.r.Dy(), hexColor(sh.fill), sh.outline)
+		} else {
+			_, err = fmt.Fprintf(w, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", sh.r.Min.X, sh.r.Min.Y, sh.r.Dx(), sh.r.Dy(), hexColor(sh.fill))
+		}
+		if err != nil {
+			return
+		}
+	}
+	for _, l := range d.labels {
+		if _, err = fmt.Fprintf(w, "<text x=\"%d\" y=\"%d\" font-family=\"sans-serif\" font-size=\"10\" text-anchor=\"middle\">%s</text>\n", l.at.X, l.at.Y, l.text); err != nil {
+			return
+		}
+	}
+	_, err = fmt.Fprintln(w, "</svg>")
+	return
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Image draws the board of s into a new image. Coordinates are not drawn, as the standard library cannot
+// render text.
+func Image(s blokus.State, o Options) *image.RGBA {
+	d := layout(s, o)
+	img := image.NewRGBA(image.Rectangle{Max: d.size})
+	drawShapes(img, d.shapes)
+	return img
+}
+
+func drawShapes(img draw.Image, shapes []shape) {
+	for _, sh := range shapes {
+		src := image.NewUniform(sh.fill)
+		if sh.outline <= 0 {
+			draw.Draw(img, sh.r, src, image.Point{}, draw.Src)
+			continue
+		}
+		t := sh.outline
+		r := sh.r
+		draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+t), src, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(r.Min.X, r.Max.Y-t, r.Max.X, r.Max.Y), src, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+t, r.Max.Y), src, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(r.Max.X-t, r.Min.Y, r.Max.X, r.Max.Y), src, image.Point{}, draw.Src)
+	}
+}
+
+// PNG writes the board of s as a PNG image to w
+func PNG(w io.Writer, s blokus.State, o Options) error {
+	return png.Encode(w, Image(s, o))
+}
+
+var gifPalette = color.Palette{
+	ColorBackground,
+	ColorEmpty,
+	ColorGrid,
+	ColorHighlight,
+	PieceColors[blokus.ColorBlue],
+	PieceColors[blokus.ColorYellow],
+	PieceColors[blokus.ColorRed],
+	PieceColors[blokus.ColorGreen],
+}
+
+// GIF writes an animated GIF to w with one frame per state. lastMoves[i] is highlighted in frame i, if
+// lastMoves is not nil. delay is the time each frame is shown, in 100ths of a second.
+func GIF(w io.Writer, states []blokus.State, lastMoves []blokus.Move, o Options, delay int) error {
+	var anim gif.GIF
+	var size image.Point
+	for i, s := range states {
+		fo := o
+		if lastMoves != nil {
+			fo.LastMove = lastMoves[i]
+		}
+		d := layout(s, fo)
+		if i == 0 {
+			size = d.size
+		}
+		// all frames must have the size of the first one
+		frame := image.NewPaletted(image.Rectangle{Max: size}, gifPalette)
+		drawShapes(frame, d.shapes)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return gif.EncodeAll(w, &anim)
+}
@@ -0,0 +1,70 @@
+package render
+
+import (
+	"bytes"
+	"github.com/hschendel/sc/2021/blokus"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func testState() *blokus.BasicState {
+	var s blokus.BasicState
+	s.SetStartPiece(blokus.PieceTetroO)
+	blokus.MustApplyMove(&s, blokus.ColorBlue, blokus.NewMove(blokus.NewTransformedPiece(blokus.PieceTetroO, blokus.RotationNone, false), 0, 0))
+	return &s
+}
+
+func TestSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := SVG(&buf, testState(), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	o := buf.String()
+	if !strings.HasPrefix(o, "<svg") || !strings.HasSuffix(o, "</svg>\n") {
+		t.Errorf("output is not an SVG document:\n%s", o)
+	}
+	if n := strings.Count(o, hexColor(PieceColors[blokus.ColorBlue])); n != 4 {
+		t.Errorf("expected 4 blue cells, but got %d", n)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PNG(&buf, testState(), Options{CellSize: 10}); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, g := 2*margin+200, img.Bounds().Dx(); e != g {
+		t.Errorf("expected width %d but got %d", e, g)
+	}
+	r, g, b, _ := img.At(margin+15, margin+15).RGBA()
+	blue := PieceColors[blokus.ColorBlue]
+	if uint8(r>>8) != blue.R || uint8(g>>8) != blue.G || uint8(b>>8) != blue.B {
+		t.Errorf("expected cell 1,1 to be blue")
+	}
+	r, g, b, _ = img.At(margin+25, margin+25).RGBA()
+	if uint8(r>>8) != ColorEmpty.R || uint8(g>>8) != ColorEmpty.G || uint8(b>>8) != ColorEmpty.B {
+		t.Errorf("expected cell 2,2 to be empty")
+	}
+}
+
+func TestGIF(t *testing.T) {
+	var empty blokus.BasicState
+	var buf bytes.Buffer
+	states := []blokus.State{&empty, testState()}
+	if err := GIF(&buf, states, nil, Options{ShowRemaining: true}, 50); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 2 {
+		t.Errorf("expected 2 frames but got %d", len(anim.Image))
+	}
+}
@@ -0,0 +1,285 @@
+package blokus
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/hschendel/sc/2021/blokus/protocol"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadReplay reads a replay file written by the official server, either plain or gzip compressed.
+// The replay is a sequence of mementos, each containing a state with the same XML as sent to the clients.
+// states contains every state of the replay, and record contains the moves inferred from the differences
+// between successive states. CurrentColor() of each state is the color of the move that was played next.
+func ReadReplay(r io.Reader) (record *GameRecord, states []*BasicState, err error) {
+	br := bufio.NewReader(r)
+	var in io.Reader = br
+	if magic, peekErr := br.Peek(2); peekErr == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(br); err != nil {
+			err = fmt.Errorf("cannot read gzip header: %s", err)
+			return
+		}
+		defer gz.Close()
+		in = gz
+	}
+
+	dec := xml.NewDecoder(in)
+	record = new(GameRecord)
+	var lastValid map[Color]bool
+	for {
+		var xs *protocol.State
+		if xs, err = nextReplayState(dec); err != nil || xs == nil {
+			break
+		}
+		s := new(BasicState)
+		var validColors map[Color]bool
+		if validColors, err = fillState(s, xs); err != nil {
+			err = fmt.Errorf("state %d: %s", len(states)+1, err)
+			break
+		}
+		for c := Color(0); c < 4; c++ {
+			s.SetColorValid(c, validColors[c])
+		}
+		if len(states) == 0 {
+			record.StartPiece = s.StartPiece()
+			record.Player1Name = xs.FirstTeam.DisplayName
+			record.Player2Name = xs.SecondTeam.DisplayName
+		} else {
+			prev := states[len(states)-1]
+			entries := inferEntries(prev, lastValid, s, validColors)
+			if len(entries) > 0 {
+				prev.SetCurrentColor(entries[0].Color)
+			}
+			record.Entries = append(record.Entries, entries...)
+		}
+		states = append(states, s)
+		lastValid = validColors
+	}
+	if err != nil {
+		record = nil
+		states = nil
+		return
+	}
+	if len(states) == 0 {
+		err = errors.New("replay contains no states")
+		record = nil
+		return
+	}
+	record.setResultFrom(states[len(states)-1])
+	return
+}
+
+// nextReplayState returns the next state contained in a room or data element of class memento, or in a
+// state element on the top level. If there are no more states, xs is nil.
+func nextReplayState(dec *xml.Decoder) (xs *protocol.State, err error) {
+	for {
+		var tok xml.Token
+		if tok, err = dec.Token(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		start, isStart := tok.(xml.StartElement)
+		if !isStart {
+			continue
+		}
+		switch start.Name.Local {
+		case "room":
+			var room protocol.Room
+			if err = dec.DecodeElement(&room, &start); err != nil {
+				return
+			}
+			if room.Data.Class == protocol.DataClassState && room.Data.State != nil {
+				xs = room.Data.State
+				return
+			}
+		case "data":
+			var data protocol.Data
+			if err = dec.DecodeElement(&data, &start); err != nil {
+				return
+			}
+			if data.Class == protocol.DataClassState && data.State != nil {
+				xs = data.State
+				return
+			}
+		case "state":
+			xs = new(protocol.State)
+			err = dec.DecodeElement(xs, &start)
+			return
+		}
+	}
+}
+
+// LoadReplay reads a replay file written by the official server from path, see ReadReplay
+func LoadReplay(path string) (record *GameRecord, states []*BasicState, err error) {
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		return
+	}
+	defer f.Close()
+	return ReadReplay(f)
+}
+
+// WriteReplay writes a recorded game as a replay in the format of the official server, so it can be watched
+// in the official GUI. Every position of the game is written as a memento, followed by the result.
+func WriteReplay(w io.Writer, record *GameRecord) (err error) {
+	var states []*BasicState
+	if states, err = Replay(record); err != nil {
+		return
+	}
+	if _, err = w.Write(protocol.ProtocolMessage); err != nil {
+		return
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("  ", "  ")
+	for i, s := range states {
+		var room protocol.Room
+		room.RoomID = replayRoomID
+		room.Data.Class = protocol.DataClassState
+		room.Data.State = replayState(record, s, i)
+		if err = enc.Encode(&room); err != nil {
+			return
+		}
+	}
+	var room protocol.Room
+	room.RoomID = replayRoomID
+	room.Data = replayResult(record)
+	if err = enc.Encode(&room); err != nil {
+		return
+	}
+	if err = enc.Flush(); err != nil {
+		return
+	}
+	_, err = io.WriteString(w, "\n</protocol>\n")
+	return
+}
+
+// SaveReplay writes a recorded game as a replay file to path, see WriteReplay. If path ends with ".gz", the
+// file is gzip compressed.
+func SaveReplay(path string, record *GameRecord) (err error) {
+	var f *os.File
+	if f, err = os.Create(path); err != nil {
+		return
+	}
+	var w io.Writer = f
+	var gz *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		gz = gzip.NewWriter(f)
+		w = gz
+	}
+	if err = WriteReplay(w, record); err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return
+}
+
+const replayRoomID = "local"
+
+// replayState converts the state after turn moves of record into its protocol representation
+func replayState(record *GameRecord, s State, turn int) *protocol.State {
+	xs := &protocol.State{
+		Class:             protocol.StateClass,
+		CurrentColorIndex: uint(s.CurrentColor()),
+		Turn:              uint(turn),
+		Round:             1 + uint(turn)/4,
+		StartPiece:        s.StartPiece().String(),
+		StartTeam:         protocol.StartTeam{Class: protocol.TeamClass, Name: protocol.TeamOne},
+		BlueShapes:        pieceNames(s.NotPlayedPiecesFor(ColorBlue)),
+		YellowShapes:      pieceNames(s.NotPlayedPiecesFor(ColorYellow)),
+		RedShapes:         pieceNames(s.NotPlayedPiecesFor(ColorRed)),
+		GreenShapes:       pieceNames(s.NotPlayedPiecesFor(ColorGreen)),
+		FirstTeam: protocol.Team{
+			DisplayName: record.Player1Name,
+			Color:       protocol.TeamColor{Class: protocol.TeamClass, Name: protocol.TeamOne},
+		},
+		SecondTeam: protocol.Team{
+			DisplayName: record.Player2Name,
+			Color:       protocol.TeamColor{Class: protocol.TeamClass, Name: protocol.TeamTwo},
+		},
+	}
+	for c := Color(0); c < 4; c++ {
+		if s.IsColorValid(c) {
+			xs.ValidColors = append(xs.ValidColors, c.String())
+		}
+		xs.LastMoveMono = append(xs.LastMoveMono, protocol.ColorEntry{Color: c.String(), Boolean: s.IsLastMoveMono(c)})
+	}
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			if c, hasPiece := s.At(x, y); hasPiece {
+				xs.Board = append(xs.Board, protocol.Field{X: x, Y: y, Content: c.String()})
+			}
+		}
+	}
+	if turn > 0 {
+		e := record.Entries[turn-1]
+		if e.Move.IsEmpty() {
+			xs.LastMove = &protocol.LastMove{Class: protocol.DataClassSkipMove, Color: e.Color.String()}
+		} else {
+			xs.LastMove = &protocol.LastMove{Class: protocol.DataClassSetMove, Piece: &protocol.Piece{
+				Color:     e.Color.String(),
+				Kind:      e.Move.Transformation.Piece().String(),
+				Rotation:  e.Move.Transformation.Rotation().String(),
+				IsFlipped: e.Move.Transformation.Flipped(),
+				Position:  protocol.Position{X: e.Move.X, Y: e.Move.Y},
+			}}
+		}
+	}
+	return xs
+}
+
+func replayResult(record *GameRecord) (data protocol.Data) {
+	data.Class = protocol.DataClassResult
+	data.Definition = &protocol.ResultDefinition{Fragments: []protocol.ResultFragment{
+		{Name: "Siegpunkte", Aggregation: "SUM", RelevantForRanking: true},
+		{Name: "\u2205 Punkte", Aggregation: "AVERAGE", RelevantForRanking: true},
+	}}
+	var winPoints1, winPoints2 uint = 1, 1
+	switch record.Result {
+	case GameResultPlayer1Won:
+		winPoints1, winPoints2 = 2, 0
+		data.Winner = &protocol.Winner{
+			DisplayName: record.Player1Name,
+			Color:       protocol.TeamColor{Class: protocol.TeamClass, Name: protocol.TeamOne},
+		}
+	case GameResultPlayer2Won:
+		winPoints1, winPoints2 = 0, 2
+		data.Winner = &protocol.Winner{
+			DisplayName: record.Player2Name,
+			Color:       protocol.TeamColor{Class: protocol.TeamClass, Name: protocol.TeamTwo},
+		}
+	}
+	data.Entries = []protocol.ResultEntry{
+		replayResultEntry(record.Player1Name, protocol.TeamOne, winPoints1, record.Score1, record.Error1),
+		replayResultEntry(record.Player2Name, protocol.TeamTwo, winPoints2, record.Score2, record.Error2),
+	}
+	return
+}
+
+func replayResultEntry(name, team string, winPoints, score uint, errMsg string) protocol.ResultEntry {
+	cause := protocol.ScoreCauseRegular
+	if errMsg != "" {
+		cause = protocol.ScoreCauseRuleViolation
+	}
+	return protocol.ResultEntry{
+		Player: protocol.ResultPlayer{Name: name, Color: protocol.TeamColor{Class: protocol.TeamClass, Name: team}},
+		Score:  protocol.Score{Cause: cause, Reason: errMsg, Parts: []uint{winPoints, score}},
+	}
+}
+
+func pieceNames(pieces []Piece) (names []string) {
+	names = make([]string, 0, len(pieces))
+	for _, p := range pieces {
+		names = append(names, p.String())
+	}
+	return
+}
@@ -0,0 +1,127 @@
+package blokus
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func testReplayState(turn uint, fields string, blueShapes []Piece, validColors string) string {
+	shapes := func(pieces []Piece) string {
+		var sb strings.Builder
+		for _, p := range pieces {
+			fmt.Fprintf(&sb, "<shape>%s</shape>", p.String())
+		}
+		return sb.String()
+	}
+	return fmt.Sprintf(`<room roomId="r1"><data class="memento"><state class="state" turn="%d" round="1" startPiece="TRIO_L">
+  <startTeam class="team">ONE</startTeam>
+  <board>%s</board>
+  <blueShapes>%s</blueShapes>
+  <yellowShapes>%s</yellowShapes>
+  <redShapes>%s</redShapes>
+  <greenShapes>%s</greenShapes>
+  <validColors>%s</validColors>
+  <first displayName="alpha"><color class="team">ONE</color></first>
+  <second displayName="beta"><color class="team">TWO</color></second>
+</state></data></room>
+`, turn, fields, shapes(blueShapes), shapes(AllPieces[:]), shapes(AllPieces[:]), shapes(AllPieces[:]), validColors)
+}
+
+func testReplayXML() string {
+	var blueAfter []Piece
+	for _, p := range AllPieces {
+		if p != PieceTrioL {
+			blueAfter = append(blueAfter, p)
+		}
+	}
+	allValid := "<color>BLUE</color><color>YELLOW</color><color>RED</color><color>GREEN</color>"
+	return "<protocol>\n" +
+		testReplayState(0, "", AllPieces[:], allValid) +
+		testReplayState(1, `<field x="19" y="18" content="BLUE"/><field x="18" y="19" content="BLUE"/><field x="19" y="19" content="BLUE"/>`, blueAfter, allValid) +
+		`<room roomId="r1"><data class="result"></data></room>` +
+		"\n</protocol>"
+}
+
+func TestReadReplay(t *testing.T) {
+	plain := testReplayXML()
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write([]byte(plain))
+	gz.Close()
+
+	inputs := map[string][]byte{
+		"plain": []byte(plain),
+		"gzip":  gzipped.Bytes(),
+	}
+	expectedMove := NewMove(NewTransformedPiece(PieceTrioL, RotationLeft, false), 18, 18)
+	for name, input := range inputs {
+		record, states, err := ReadReplay(bytes.NewReader(input))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err)
+			continue
+		}
+		if len(states) != 2 {
+			t.Errorf("%s: expected 2 states but got %d", name, len(states))
+			continue
+		}
+		if record.StartPiece != PieceTrioL || record.Player1Name != "alpha" || record.Player2Name != "beta" {
+			t.Errorf("%s: unexpected record header %+v", name, record)
+		}
+		if len(record.Entries) != 1 {
+			t.Errorf("%s: expected 1 move but got %d", name, len(record.Entries))
+			continue
+		}
+		e := record.Entries[0]
+		if e.Color != ColorBlue || !e.Move.Equal(expectedMove) {
+			t.Errorf("%s: expected move\n%s\nof BLUE but got\n%s\nof %s", name, expectedMove.FormatPretty('E', "  "), e.Move.FormatPretty('G', "  "), e.Color)
+		}
+		if !states[1].IsPiecePlayed(ColorBlue, PieceTrioL) {
+			t.Errorf("%s: expected TRIO_L to be played by BLUE in second state", name)
+		}
+		if record.Score1 != 3 || record.Result != GameResultPlayer1Won {
+			t.Errorf("%s: expected result PLAYER1 with score 3 but got %s with %d", name, record.Result, record.Score1)
+		}
+	}
+}
+
+func TestWriteReplay(t *testing.T) {
+	var record GameRecord
+	m := Match{
+		Player1:     firstMovePlayer{},
+		Player2:     firstMovePlayer{},
+		Player1Name: "one",
+		Player2Name: "two",
+		Seed:        7,
+		Record:      &record,
+	}
+	m.Run()
+
+	var buf bytes.Buffer
+	if err := WriteReplay(&buf, &record); err != nil {
+		t.Fatalf("cannot write replay: %s", err)
+	}
+	read, states, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatalf("cannot read written replay: %s", err)
+	}
+	if len(states) != len(record.Entries)+1 {
+		t.Errorf("expected %d states but got %d", len(record.Entries)+1, len(states))
+	}
+	if read.Player1Name != "one" || read.Player2Name != "two" || read.StartPiece != record.StartPiece {
+		t.Errorf("unexpected record header %+v", read)
+	}
+	if read.Result != record.Result || read.Score1 != record.Score1 || read.Score2 != record.Score2 {
+		t.Errorf("expected result %s %d:%d but got %s %d:%d", record.Result, record.Score1, record.Score2, read.Result, read.Score1, read.Score2)
+	}
+	if len(read.Entries) != len(record.Entries) {
+		t.Fatalf("expected %d moves but got %d", len(record.Entries), len(read.Entries))
+	}
+	for i, e := range record.Entries {
+		if read.Entries[i].Color != e.Color || !read.Entries[i].Move.Equal(e.Move) {
+			t.Errorf("move %d differs", i)
+		}
+	}
+}
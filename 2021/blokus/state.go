@@ -52,7 +52,10 @@ func CopyState(into MutableState, from State) {
 		color := Color(c)
 		into.SetNotPlayedPiecesFor(color, from.NotPlayedPiecesFor(color))
 		into.SetLastMoveMono(color, from.IsLastMoveMono(color))
+		into.SetColorValid(color, from.IsColorValid(color))
 	}
+	into.SetCurrentColor(from.CurrentColor())
+	into.SetPlayerOneFirst(from.IsPlayerOneFirst())
 }
 
 // IsCurrentPlayerOne returns true if the first player is the current player
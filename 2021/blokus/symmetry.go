@@ -0,0 +1,215 @@
+package blokus
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Symmetry is one of the 8 symmetries of the board. As every color can start in any corner, and a color's start
+// corner is only determined by its first piece, each of them turns a legal position into a legal position with the
+// same colors to move, and the same moves turned accordingly.
+//
+// The value is a bit set: bit 2 swaps x and y, then bit 0 mirrors x and bit 1 mirrors y.
+type Symmetry uint8
+
+const (
+	SymmetryIdentity = Symmetry(iota)
+	SymmetryMirrorX
+	SymmetryMirrorY
+	SymmetryRotate180
+	SymmetryTranspose
+	SymmetryRotateRight
+	SymmetryRotateLeft
+	SymmetryAntiTranspose
+)
+
+// NumSymmetries is the number of symmetries of the board
+const NumSymmetries = 8
+
+func (s Symmetry) String() string {
+	switch s {
+	case SymmetryIdentity:
+		return "IDENTITY"
+	case SymmetryMirrorX:
+		return "MIRROR_X"
+	case SymmetryMirrorY:
+		return "MIRROR_Y"
+	case SymmetryRotate180:
+		return "ROTATE_180"
+	case SymmetryTranspose:
+		return "TRANSPOSE"
+	case SymmetryRotateRight:
+		return "ROTATE_RIGHT"
+	case SymmetryRotateLeft:
+		return "ROTATE_LEFT"
+	case SymmetryAntiTranspose:
+		return "ANTI_TRANSPOSE"
+	default:
+		panic(fmt.Sprintf("unknown Symmetry value: %d", s))
+	}
+}
+
+func ParseSymmetry(s string) (sym Symmetry, err error) {
+	switch s {
+	case "IDENTITY":
+		sym = SymmetryIdentity
+	case "MIRROR_X":
+		sym = SymmetryMirrorX
+	case "MIRROR_Y":
+		sym = SymmetryMirrorY
+	case "ROTATE_180":
+		sym = SymmetryRotate180
+	case "TRANSPOSE":
+		sym = SymmetryTranspose
+	case "ROTATE_RIGHT":
+		sym = SymmetryRotateRight
+	case "ROTATE_LEFT":
+		sym = SymmetryRotateLeft
+	case "ANTI_TRANSPOSE":
+		sym = SymmetryAntiTranspose
+	default:
+		err = fmt.Errorf("invalid symmetry %q", s)
+	}
+	return
+}
+
+// Inverse returns the symmetry that undoes s
+func (s Symmetry) Inverse() Symmetry {
+	switch s {
+	case SymmetryRotateRight:
+		return SymmetryRotateLeft
+	case SymmetryRotateLeft:
+		return SymmetryRotateRight
+	default:
+		return s
+	}
+}
+
+// Position returns the board cell p is moved to by s
+func (s Symmetry) Position(p Position) Position {
+	return s.positionIn(p, 20, 20)
+}
+
+// positionIn applies s to p in a box of width w and height h, with the box turned accordingly
+func (s Symmetry) positionIn(p Position, w, h uint8) Position {
+	if s&SymmetryTranspose != 0 {
+		p.X, p.Y = p.Y, p.X
+		w, h = h, w
+	}
+	if s&SymmetryMirrorX != 0 {
+		p.X = w - 1 - p.X
+	}
+	if s&SymmetryMirrorY != 0 {
+		p.Y = h - 1 - p.Y
+	}
+	return p
+}
+
+var symmetryTransformationsOnce sync.Once
+
+// symmetryTransformations contains the unique transformation each TransformedPiece is turned into by a symmetry
+var symmetryTransformations [256][NumSymmetries]TransformedPiece
+
+func initSymmetryTransformations() {
+	for p := PieceMono; p < NumPieces; p++ {
+		for rotation := RotationNone; rotation < 4; rotation++ {
+			for _, flipped := range []bool{false, true} {
+				tp := NewTransformedPiece(p, rotation, flipped)
+				for s := Symmetry(0); s < NumSymmetries; s++ {
+					positions := make([]Position, 0, len(tp.Positions()))
+					for _, pos := range tp.Positions() {
+						positions = append(positions, s.positionIn(pos, tp.Width(), tp.Height()))
+					}
+					for _, utp := range uniquePieceTransformations[p] {
+						if PositionsEqual(positions, utp.Positions()) {
+							symmetryTransformations[tp][s] = utp
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// TransformedPiece returns the transformation of the same piece that looks like tp with s applied. It is always
+// one of the unique transformations also used by PossibleNextMoves.
+func (s Symmetry) TransformedPiece(tp TransformedPiece) TransformedPiece {
+	symmetryTransformationsOnce.Do(initSymmetryTransformations)
+	return symmetryTransformations[tp][s]
+}
+
+// Move returns m with s applied, so that it covers the cells of m moved by s. The empty move stays empty.
+func (s Symmetry) Move(m Move) Move {
+	if !m.IsMove {
+		return m
+	}
+	w, h := m.Transformation.Width(), m.Transformation.Height()
+	// the top left corner of the box of the piece moves to one of the corners of the moved box
+	corner := s.Position(Position{X: m.X, Y: m.Y})
+	opposite := s.Position(Position{X: m.X + w - 1, Y: m.Y + h - 1})
+	if opposite.X < corner.X {
+		corner.X = opposite.X
+	}
+	if opposite.Y < corner.Y {
+		corner.Y = opposite.Y
+	}
+	return NewMove(s.TransformedPiece(m.Transformation), corner.X, corner.Y)
+}
+
+// TransformState copies from into into, with s applied to the board
+func TransformState(into MutableState, from State, s Symmetry) {
+	CopyState(into, from)
+	for x := uint8(0); x < 20; x++ {
+		for y := uint8(0); y < 20; y++ {
+			p := s.Position(Position{X: x, Y: y})
+			color, hasPiece := from.At(x, y)
+			into.Set(p.X, p.Y, color, hasPiece)
+		}
+	}
+}
+
+// CanonicalSymmetry returns the symmetry that turns the board of s into the smallest board of all symmetries,
+// comparing the cells row by row, with empty cells before the colors. Positions that are equal up to symmetry
+// have the same canonical board, e.g. for looking them up in an opening book.
+func CanonicalSymmetry(s State) (canonical Symmetry) {
+	var cells [20][20]uint8
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			if c, hasPiece := s.At(x, y); hasPiece {
+				cells[y][x] = uint8(c) + 1
+			}
+		}
+	}
+	for sym := Symmetry(1); sym < NumSymmetries; sym++ {
+		if compareSymmetricBoards(&cells, sym.Inverse(), canonical.Inverse()) < 0 {
+			canonical = sym
+		}
+	}
+	return
+}
+
+// compareSymmetricBoards compares the boards of cells turned by the symmetries whose inverses are a and b
+func compareSymmetricBoards(cells *[20][20]uint8, a, b Symmetry) int {
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			pa, pb := a.Position(Position{X: x, Y: y}), b.Position(Position{X: x, Y: y})
+			ca, cb := cells[pa.Y][pa.X], cells[pb.Y][pb.X]
+			if ca != cb {
+				if ca < cb {
+					return -1
+				}
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+// Canonicalize copies from into into, turned by CanonicalSymmetry, and returns the symmetry applied. Moves found for
+// into can be turned back with the inverse symmetry.
+func Canonicalize(into MutableState, from State) (s Symmetry) {
+	s = CanonicalSymmetry(from)
+	TransformState(into, from, s)
+	return
+}
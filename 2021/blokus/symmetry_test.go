@@ -0,0 +1,127 @@
+package blokus
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSymmetry_StringParse(t *testing.T) {
+	for s := Symmetry(0); s < NumSymmetries; s++ {
+		if parsed, err := ParseSymmetry(s.String()); err != nil || parsed != s {
+			t.Errorf("expected to parse %s but got %d, %v", s.String(), parsed, err)
+		}
+	}
+}
+
+func TestSymmetry_Position(t *testing.T) {
+	if p := SymmetryRotateRight.Position(Position{X: 0, Y: 0}); p != (Position{X: 19, Y: 0}) {
+		t.Errorf("expected ROTATE_RIGHT to move the top left corner to the top right, but got %v", p)
+	}
+	for s := Symmetry(0); s < NumSymmetries; s++ {
+		corners := make(map[Position]bool)
+		for _, c := range StartCorners {
+			p := s.Position(c)
+			if !IsStartCorner(p.X, p.Y) {
+				t.Errorf("%s: expected corner %v to stay a corner, but got %v", s.String(), c, p)
+			}
+			corners[p] = true
+			if back := s.Inverse().Position(p); back != c {
+				t.Errorf("%s: expected the inverse to move %v back, but got %v", s.String(), c, back)
+			}
+		}
+		if len(corners) != 4 {
+			t.Errorf("%s: expected the corners to be permuted, but got %v", s.String(), corners)
+		}
+	}
+}
+
+func TestSymmetry_TransformedPiece(t *testing.T) {
+	for _, p := range AllPieces {
+		for _, tp := range p.Transformations() {
+			for s := Symmetry(0); s < NumSymmetries; s++ {
+				stp := s.TransformedPiece(tp)
+				if stp.Piece() != p {
+					t.Fatalf("%s %s: expected piece %s but got %s", tp.String(), s.String(), p.String(), stp.String())
+				}
+				expected := make([]Position, 0, len(tp.Positions()))
+				for _, pos := range tp.Positions() {
+					expected = append(expected, s.positionIn(pos, tp.Width(), tp.Height()))
+				}
+				if !PositionsEqual(stp.Positions(), expected) {
+					t.Errorf("%s %s: expected positions %v but got %v", tp.String(), s.String(), expected, stp.Positions())
+				}
+			}
+		}
+	}
+}
+
+// TestSymmetry_CanApplyMove plays a game with random moves, and checks for every position and symmetry that a move
+// can be applied exactly if the transformed move can be applied to the transformed position
+func TestSymmetry_CanApplyMove(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var s BasicState
+	g := NewGame(&s, PiecePentoL)
+	var ts BasicState
+	for !g.IsOver() {
+		c := g.CurrentColor()
+		moves := g.LegalMoves()
+		for sym := Symmetry(0); sym < NumSymmetries; sym++ {
+			TransformState(&ts, &s, sym)
+			if expected, got := len(moves), len(PossibleNextMoves(&ts, c)); expected != got {
+				t.Fatalf("turn %d, %s: expected %d possible moves but got %d", g.Turn(), sym.String(), expected, got)
+			}
+			for _, m := range moves {
+				if !CanApplyMove(&ts, c, sym.Move(m)) {
+					t.Fatalf("turn %d, %s: cannot apply %s turned into %s", g.Turn(), sym.String(), m.String(), sym.Move(m).String())
+				}
+			}
+			for i := 0; i < 200; i++ {
+				tps := AllPieces[rnd.Intn(NumPieces)].Transformations()
+				m := NewMove(tps[rnd.Intn(len(tps))], uint8(rnd.Intn(20)), uint8(rnd.Intn(20)))
+				if !IsOnBoard(m.Transformation, m.X, m.Y) {
+					continue
+				}
+				if CanApplyMove(&s, c, m) != CanApplyMove(&ts, c, sym.Move(m)) {
+					t.Fatalf("turn %d, %s: expected the same result for %s and %s", g.Turn(), sym.String(), m.String(), sym.Move(m).String())
+				}
+				if back := sym.Inverse().Move(sym.Move(m)); !back.Equal(m) {
+					t.Fatalf("%s: expected the inverse to turn %s back, but got %s", sym.String(), m.String(), back.String())
+				}
+			}
+		}
+		m := EmptyMove
+		if len(moves) > 0 {
+			m = moves[rnd.Intn(len(moves))]
+		}
+		if err := g.Play(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	var s BasicState
+	g := NewGame(&s, PiecePentoL)
+	for i := 0; i < 8; i++ {
+		if err := g.Play(g.LegalMoves()[i%len(g.LegalMoves())]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var canonical BasicState
+	Canonicalize(&canonical, &s)
+	expected := FormatPosition(&canonical)
+	for sym := Symmetry(0); sym < NumSymmetries; sym++ {
+		var ts, tc BasicState
+		TransformState(&ts, &s, sym)
+		applied := Canonicalize(&tc, &ts)
+		if got := FormatPosition(&tc); got != expected {
+			t.Errorf("%s: expected canonical position %s but got %s", sym.String(), expected, got)
+		}
+		// turning back the canonical position leads to the position canonicalized
+		var back BasicState
+		TransformState(&back, &tc, applied.Inverse())
+		if FormatPosition(&back) != FormatPosition(&ts) {
+			t.Errorf("%s: expected the inverse of %s to turn the canonical position back", sym.String(), applied.String())
+		}
+	}
+}
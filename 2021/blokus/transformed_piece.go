@@ -72,13 +72,7 @@ func (p *TransformedPiece) Positions() []Position {
 }
 
 func (p *TransformedPiece) Fills(x, y uint8) bool {
-	positions := p.Positions()
-	for _, pos := range positions {
-		if pos.X == x && pos.Y == y {
-			return true
-		}
-	}
-	return false
+	return ShapeOf(*p).Mask&MaskBit(x, y) != 0
 }
 
 func (p *TransformedPiece) PrettyFormat(r rune, indent string) string {
@@ -114,3 +108,10 @@ func (p *TransformedPiece) PrettyPrint(r rune, indent string, w io.Writer) (err
 func (p Piece) Transformations() []TransformedPiece {
 	return uniquePieceTransformations[p]
 }
+
+// Canonical returns the transformation of Piece.Transformations() that covers the same positions as p. Different
+// transformations of a symmetric piece can be equal, e.g. every rotation of PENTO_X, so comparing the canonical
+// transformations is the same as comparing the positions.
+func (p *TransformedPiece) Canonical() TransformedPiece {
+	return ShapeOf(*p).Canonical
+}
@@ -0,0 +1,85 @@
+package tune
+
+import (
+	"github.com/hschendel/sc"
+	"github.com/hschendel/sc/2021/blokus"
+	"log"
+	"math/rand"
+)
+
+// WeightedPlayer plays the move leading to the position with the highest Weights.Evaluate. Among equally rated moves
+// it prefers larger pieces, and then picks one using Rand, or the first one if Rand is nil.
+type WeightedPlayer struct {
+	// Weights are used for rating moves. If it is nil, they are loaded from WeightsFile, or DefaultWeights are used
+	// if that is empty or cannot be loaded.
+	Weights     Weights
+	WeightsFile string
+	Rand        *rand.Rand
+
+	lastEvaluation  []blokus.Evaluation
+	lastSearchStats blokus.SearchStats
+}
+
+func (p *WeightedPlayer) NextMove(state blokus.State, color blokus.Color, timeout sc.Timeout) blokus.Move {
+	w := p.weights()
+	moves := blokus.PossibleNextMoves(state, color)
+	if len(moves) == 0 {
+		return blokus.EmptyMove
+	}
+	var s blokus.BasicState
+	blokus.CopyState(&s, state)
+	var best []blokus.Move
+	var bestRating float64
+	var bestPoints uint
+	for _, m := range moves {
+		blokus.MustApplyMove(&s, color, m)
+		rating := w.Evaluate(&s, color)
+		blokus.UndoMove(&s, color, m)
+		points := m.Transformation.Piece().NumPoints()
+		if len(best) == 0 || rating > bestRating || (rating == bestRating && points > bestPoints) {
+			best, bestRating, bestPoints = best[:0], rating, points
+		} else if rating != bestRating || points != bestPoints {
+			continue
+		}
+		best = append(best, m)
+	}
+	move := best[0]
+	if p.Rand != nil {
+		move = best[p.Rand.Intn(len(best))]
+	}
+	p.lastEvaluation = []blokus.Evaluation{
+		{Name: "rating", Value: bestRating},
+		{Name: "possible moves", Value: float64(len(moves))},
+		{Name: "equally rated moves", Value: float64(len(best))},
+	}
+	p.lastSearchStats = blokus.SearchStats{Nodes: uint64(len(moves)), Depth: 1}
+	return move
+}
+
+func (p *WeightedPlayer) End() {
+}
+
+// LastEvaluation reports the rating of the last move picked, see blokus.EvaluationReporter
+func (p *WeightedPlayer) LastEvaluation() []blokus.Evaluation {
+	return p.lastEvaluation
+}
+
+// LastSearchStats reports the number of moves rated for the last move, see blokus.SearchStatsReporter
+func (p *WeightedPlayer) LastSearchStats() blokus.SearchStats {
+	return p.lastSearchStats
+}
+
+func (p *WeightedPlayer) weights() Weights {
+	if p.Weights != nil {
+		return p.Weights
+	}
+	p.Weights = DefaultWeights()
+	if p.WeightsFile != "" {
+		if w, err := LoadWeights(p.WeightsFile); err != nil {
+			log.Printf("using default weights: %s", err)
+		} else {
+			p.Weights = w
+		}
+	}
+	return p.Weights
+}
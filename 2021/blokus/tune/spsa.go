@@ -0,0 +1,158 @@
+package tune
+
+import (
+	"fmt"
+	"github.com/hschendel/sc/2021/blokus"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Defaults for the fields of SPSA
+const (
+	DefaultIterations        = 100
+	DefaultGamesPerIteration = 4
+	DefaultStepSize          = 0.0005
+	DefaultPerturbation      = 0.1
+)
+
+// SPSA tunes weights with simultaneous perturbation stochastic approximation. In every iteration, all weights are
+// moved up or down by a random sign at the same time, and a player with the weights moved up plays a MatchSeries
+// against a player with the weights moved down. The weights are then moved in the direction of the winner,
+// proportional to the average score difference. After that, a player with the new weights plays the same number of
+// games against a player with the start weights, and the weights that did best against the start weights are the
+// result.
+//
+// All randomness comes from Seed, so a run with the same settings plays the same games, as long as no player
+// runs into a timeout.
+type SPSA struct {
+	// Start contains the features to tune and their initial weights, DefaultWeights if nil
+	Start Weights
+	// Iterations is DefaultIterations if 0
+	Iterations uint
+	// GamesPerIteration is DefaultGamesPerIteration if 0. It is rounded up to an even number, so that both players
+	// move first equally often.
+	GamesPerIteration uint
+	// StepSize scales the changes of the weights, DefaultStepSize if 0
+	StepSize float64
+	// Perturbation is the amount the weights are moved up and down for the games, DefaultPerturbation if 0
+	Perturbation float64
+	Seed         int64
+	// MoveTimeout and HardMoveTimeout are used for every game, see blokus.Match
+	MoveTimeout     time.Duration
+	HardMoveTimeout time.Duration
+	// LogTo receives a line per iteration, if set
+	LogTo io.Writer
+	// OnIteration is called after every iteration, if set
+	OnIteration func(it *Iteration)
+}
+
+// Iteration is the outcome of a single iteration of SPSA
+type Iteration struct {
+	// Number counts the iterations, starting at 1
+	Number uint
+	// Plus and Minus are the weights that played against each other
+	Plus  Weights
+	Minus Weights
+	// Result of the games, player 1 is the one with the Plus weights
+	Result blokus.RepeatGameResult
+	// ScoreDiff is the average score of Plus minus the average score of Minus
+	ScoreDiff float64
+	// Weights after the iteration
+	Weights Weights
+	// Baseline is the result of the games of Weights, as player 1, against the start weights
+	Baseline blokus.RepeatGameResult
+	// BaselineScoreDiff is the average score of Weights minus the average score of the start weights
+	BaselineScoreDiff float64
+	// Best is true if Weights did better against the start weights than the weights of all previous iterations
+	Best bool
+}
+
+// Run tunes the weights and returns the weights of the iteration with the highest BaselineScoreDiff. As the
+// iterations are noisy, these are usually better than the weights after the last iteration. If no iteration did
+// better than the start weights, the start weights are returned.
+func (t *SPSA) Run() (best Weights) {
+	start := t.Start.Clone()
+	if t.Start == nil {
+		start = DefaultWeights()
+	}
+	w, best := start, start
+	var bestScoreDiff float64
+	names := w.Names()
+	iterations := t.Iterations
+	if iterations == 0 {
+		iterations = DefaultIterations
+	}
+	games := t.GamesPerIteration
+	if games == 0 {
+		games = DefaultGamesPerIteration
+	}
+	games += games % 2
+	stepSize := t.StepSize
+	if stepSize == 0 {
+		stepSize = DefaultStepSize
+	}
+	perturbation := t.Perturbation
+	if perturbation == 0 {
+		perturbation = DefaultPerturbation
+	}
+	// the usual SPSA gain sequences, with a stability constant of a tenth of the iterations
+	stability := float64(iterations) / 10
+	rnd := rand.New(rand.NewSource(t.Seed))
+
+	for k := uint(0); k < iterations; k++ {
+		a := stepSize / math.Pow(float64(k)+1+stability, 0.602)
+		c := perturbation / math.Pow(float64(k)+1, 0.101)
+		delta := make(map[string]float64, len(names))
+		it := Iteration{Number: k + 1, Plus: make(Weights, len(names)), Minus: make(Weights, len(names))}
+		for _, name := range names {
+			delta[name] = float64(2*rnd.Intn(2) - 1)
+			it.Plus[name] = w[name] + c*delta[name]
+			it.Minus[name] = w[name] - c*delta[name]
+		}
+		it.Result = t.play(rnd, it.Plus, it.Minus, "plus", "minus", games)
+		it.ScoreDiff = it.Result.Player1AvgScore - it.Result.Player2AvgScore
+		w = w.Clone()
+		for _, name := range names {
+			w[name] += a * it.ScoreDiff / (2 * c * delta[name])
+		}
+		it.Weights = w
+		it.Baseline = t.play(rnd, w, start, "tuned", "start", games)
+		it.BaselineScoreDiff = it.Baseline.Player1AvgScore - it.Baseline.Player2AvgScore
+		if it.BaselineScoreDiff > bestScoreDiff {
+			best, bestScoreDiff = w, it.BaselineScoreDiff
+			it.Best = true
+		}
+		if t.LogTo != nil {
+			fmt.Fprintf(t.LogTo, "iteration %3d: plus %d:%d minus, score diff %+.1f, against start %d:%d, score diff %+.1f, weights",
+				it.Number, it.Result.Player1Wins, it.Result.Player2Wins, it.ScoreDiff, it.Baseline.Player1Wins, it.Baseline.Player2Wins, it.BaselineScoreDiff)
+			for _, name := range names {
+				fmt.Fprintf(t.LogTo, " %s=%.4f", name, w[name])
+			}
+			if it.Best {
+				fmt.Fprint(t.LogTo, " (best)")
+			}
+			fmt.Fprintln(t.LogTo)
+		}
+		if t.OnIteration != nil {
+			t.OnIteration(&it)
+		}
+	}
+	return
+}
+
+// play runs a series of games between players with the weights w1 and w2, with all randomness taken from rnd
+func (t *SPSA) play(rnd *rand.Rand, w1, w2 Weights, name1, name2 string, games uint) blokus.RepeatGameResult {
+	series := blokus.MatchSeries{
+		Player1:         &WeightedPlayer{Weights: w1, Rand: rand.New(rand.NewSource(rnd.Int63()))},
+		Player2:         &WeightedPlayer{Weights: w2, Rand: rand.New(rand.NewSource(rnd.Int63()))},
+		Player1Name:     name1,
+		Player2Name:     name2,
+		Repetitions:     games,
+		Seed:            rnd.Int63() | 1,
+		MoveTimeout:     t.MoveTimeout,
+		HardMoveTimeout: t.HardMoveTimeout,
+	}
+	return series.Run()
+}
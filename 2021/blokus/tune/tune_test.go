@@ -0,0 +1,84 @@
+package tune
+
+import (
+	"github.com/hschendel/sc"
+	"github.com/hschendel/sc/2021/blokus"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadWeights(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "weights.json")
+	w := DefaultWeights()
+	w["cells"] = 1.5
+	if err = SaveWeights(path, w); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadWeights(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(w, loaded) {
+		t.Errorf("expected %v but loaded %v", w, loaded)
+	}
+	if err = SaveWeights(path, Weights{"no such feature": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadWeights(path); err == nil {
+		t.Errorf("expected an error for an unknown feature")
+	}
+}
+
+func TestWeightedPlayer(t *testing.T) {
+	s := blokus.NewGame(new(blokus.BasicState), blokus.PieceTetroO).State()
+	p := WeightedPlayer{Weights: Weights{"cells": 1}, Rand: rand.New(rand.NewSource(1))}
+	m := p.NextMove(s, blokus.ColorBlue, sc.NewTimeout(time.Second))
+	if !blokus.CanApplyMove(s, blokus.ColorBlue, m) || m.IsEmpty() {
+		t.Errorf("expected a legal move, but got %s", m.String())
+	}
+	if p.LastSearchStats().Nodes == 0 {
+		t.Errorf("expected search stats")
+	}
+}
+
+func TestSPSA(t *testing.T) {
+	start := Weights{"cells": 1, "anchors": 0}
+	run := func() (w Weights, iterations int) {
+		best := start
+		tuner := SPSA{
+			Start:             start,
+			Iterations:        1,
+			GamesPerIteration: 2,
+			Seed:              7,
+			OnIteration: func(it *Iteration) {
+				iterations++
+				if it.Best {
+					best = it.Weights
+				}
+			},
+		}
+		w = tuner.Run()
+		if !reflect.DeepEqual(w, best) {
+			t.Errorf("expected the best weights %v, but got %v", best, w)
+		}
+		return
+	}
+	w1, iterations := run()
+	if iterations != 1 {
+		t.Fatalf("expected 1 iteration but got %d", iterations)
+	}
+	w2, _ := run()
+	if !reflect.DeepEqual(w1, w2) {
+		t.Errorf("expected the same weights for the same seed, but got %v and %v", w1, w2)
+	}
+}
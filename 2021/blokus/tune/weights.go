@@ -0,0 +1,108 @@
+// Package tune optimizes the weights of evaluation features by letting players with different weights play against
+// each other. The weights are stored as JSON, so that WeightedPlayer can load them.
+package tune
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hschendel/sc/2021/blokus"
+	"github.com/hschendel/sc/2021/blokus/eval"
+	"os"
+	"sort"
+)
+
+// Weights maps names of features in eval.All to their weights. Features that are missing have the weight 0.
+type Weights map[string]float64
+
+// DefaultWeights rates positions like example_players.QuickPlayer, and adds the cheap features that are not too
+// slow for self-play with zero weight, so that they are tuned too
+func DefaultWeights() Weights {
+	return Weights{
+		"cells":             1,
+		"bounding box area": 0.1,
+		"anchors":           0,
+		"reachable area":    0,
+		"center distance":   0,
+		"enemy contacts":    0,
+	}
+}
+
+// Names returns the sorted feature names of w
+func (w Weights) Names() (names []string) {
+	names = make([]string, 0, len(w))
+	for name := range w {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// Clone returns a copy of w
+func (w Weights) Clone() (c Weights) {
+	c = make(Weights, len(w))
+	for name, v := range w {
+		c[name] = v
+	}
+	return
+}
+
+// Validate returns an error if w contains a name that is not in eval.All
+func (w Weights) Validate() error {
+	for _, name := range w.Names() {
+		if _, found := feature(name); !found {
+			return fmt.Errorf("unknown feature %q", name)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the weighted sum of the features of s from the view of the player owning c, see eval.PlayerDiff.
+// Features with weight 0 are not calculated.
+func (w Weights) Evaluate(s blokus.State, c blokus.Color) (rating float64) {
+	for _, f := range eval.All {
+		if weight := w[f.Name]; weight != 0 {
+			rating += weight * eval.PlayerDiff(s, c, f.Value)
+		}
+	}
+	return
+}
+
+func feature(name string) (f eval.Feature, found bool) {
+	for _, f = range eval.All {
+		if f.Name == name {
+			found = true
+			return
+		}
+	}
+	return
+}
+
+// LoadWeights reads weights saved with SaveWeights
+func LoadWeights(path string) (w Weights, err error) {
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		return
+	}
+	defer f.Close()
+	if err = json.NewDecoder(f).Decode(&w); err != nil {
+		err = fmt.Errorf("cannot parse weights file %s: %s", path, err)
+		return
+	}
+	err = w.Validate()
+	return
+}
+
+// SaveWeights writes w as JSON to path
+func SaveWeights(path string, w Weights) (err error) {
+	var f *os.File
+	if f, err = os.Create(path); err != nil {
+		return
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(w); err != nil {
+		f.Close()
+		return
+	}
+	return f.Close()
+}
@@ -0,0 +1,28 @@
+package webui
+
+import (
+	"flag"
+	"fmt"
+	"github.com/hschendel/sc/2021/blokus"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Main provides the main function for a command line tool that serves the web UI for the given players.
+// You can invoke the executable like this: <name of executable> [-addr localhost:8080] [-delay 300ms]
+// and then open the address in the browser.
+func Main(players map[string]blokus.Player) {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	delay := fs.Duration("delay", 300*time.Millisecond, "pause after every move")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		os.Exit(1)
+	}
+	fmt.Printf("open http://%s/ in your browser\n", *addr)
+	if err := http.ListenAndServe(*addr, NewServer(players, *delay)); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot serve: %s\n", err)
+		os.Exit(2)
+	}
+}
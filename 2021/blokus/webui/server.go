@@ -0,0 +1,385 @@
+// Package webui serves a small web page on which games between players can be watched and played in the browser.
+// The page receives updates as server-sent events, and sends the moves of a human player as JSON requests, so only
+// the standard library is needed.
+package webui
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hschendel/sc"
+	"github.com/hschendel/sc/2021/blokus"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HumanPlayerName is the name under which a human playing in the browser can be selected as a player
+const HumanPlayerName = "human"
+
+//go:embed index.html
+var static embed.FS
+
+// Server runs one game at a time and serves its progress to the browser
+type Server struct {
+	// Players can be selected for a game by their name, see HumanPlayerName for the human player
+	Players map[string]blokus.Player
+	// MoveDelay is waited after every move, so that games between bots can be watched live
+	MoveDelay time.Duration
+
+	mux       *http.ServeMux
+	mu        sync.Mutex
+	game      *game
+	nextID    int
+	listeners map[chan struct{}]bool
+}
+
+// NewServer returns a Server for the given players
+func NewServer(players map[string]blokus.Player, moveDelay time.Duration) *Server {
+	s := &Server{
+		Players:   players,
+		MoveDelay: moveDelay,
+		mux:       http.NewServeMux(),
+		listeners: make(map[chan struct{}]bool),
+	}
+	s.mux.Handle("/", http.FileServer(http.FS(static)))
+	s.mux.HandleFunc("/api/players", s.handlePlayers)
+	s.mux.HandleFunc("/api/new", s.handleNew)
+	s.mux.HandleFunc("/api/game", s.handleGame)
+	s.mux.HandleFunc("/api/events", s.handleEvents)
+	s.mux.HandleFunc("/api/legal", s.handleLegal)
+	s.mux.HandleFunc("/api/move", s.handleMove)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// game is the game currently shown. All fields are protected by Server.mu.
+type game struct {
+	id     int
+	record blokus.GameRecord
+	states []*blokus.BasicState
+	done   bool
+	// waiting is set while the human player is asked for a move for waitingColor in waitingState
+	waiting      bool
+	waitingColor blokus.Color
+	waitingState blokus.BasicState
+	input        chan humanInput
+	// quit is closed to stop the game, and finished is closed once its Match has returned
+	quit     chan struct{}
+	finished chan struct{}
+}
+
+type humanInput struct {
+	move blokus.Move
+	undo bool
+}
+
+// StartGame ends the current game, and starts a new one between the players with the given names. It waits for the
+// current game to stop before the new one starts, so that both games do not ask a player at the same time. Only a
+// move the current game stopped waiting for after the hard timeout, see blokus.Match.Stop, can still be running.
+func (s *Server) StartGame(player1Name, player2Name string, seed int64) (err error) {
+	var players [2]blokus.Player
+	for i, name := range []string{player1Name, player2Name} {
+		if players[i], err = s.player(name); err != nil {
+			return
+		}
+	}
+
+	s.mu.Lock()
+	previous := s.game
+	if previous != nil && !previous.done {
+		close(previous.quit)
+	}
+	s.nextID++
+	g := &game{
+		id:       s.nextID,
+		input:    make(chan humanInput),
+		quit:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	m := blokus.Match{
+		Player1:     players[0],
+		Player2:     players[1],
+		Player1Name: player1Name,
+		Player2Name: player2Name,
+		Seed:        seed,
+		Stop:        g.quit,
+	}
+	g.record = blokus.GameRecord{StartPiece: m.StartPiece(), Player1Name: player1Name, Player2Name: player2Name, Seed: seed}
+	g.states, _ = blokus.Replay(&g.record)
+	for i, name := range []string{player1Name, player2Name} {
+		if name == HumanPlayerName {
+			hp := &humanPlayer{server: s, game: g}
+			if i == 0 {
+				m.Player1 = hp
+			} else {
+				m.Player2 = hp
+			}
+		}
+	}
+	s.game = g
+	s.notify()
+	s.mu.Unlock()
+	if previous != nil {
+		<-previous.finished
+	}
+
+	m.Record = new(blokus.GameRecord)
+	m.OnMove = func(entries []blokus.GameRecordEntry) {
+		s.moved(g, entries)
+	}
+	go func() {
+		defer close(g.finished)
+		m.Run()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		g.record = *m.Record
+		g.states, _ = blokus.Replay(&g.record)
+		g.done = true
+		g.waiting = false
+		s.notify()
+	}()
+	return
+}
+
+func (s *Server) player(name string) (p blokus.Player, err error) {
+	if name == HumanPlayerName {
+		return
+	}
+	if p = s.Players[name]; p == nil {
+		err = fmt.Errorf("unknown player %q", name)
+	}
+	return
+}
+
+// moved updates g after a move, and waits for MoveDelay if g is still the current game
+func (s *Server) moved(g *game, entries []blokus.GameRecordEntry) {
+	s.mu.Lock()
+	g.record.Entries = append(g.record.Entries[:0:0], entries...)
+	g.states, _ = blokus.Replay(&g.record)
+	current := s.game == g
+	s.notify()
+	s.mu.Unlock()
+	if current && s.MoveDelay > 0 {
+		select {
+		case <-time.After(s.MoveDelay):
+		case <-g.quit:
+		}
+	}
+}
+
+// notify wakes up all event listeners. s.mu must be locked.
+func (s *Server) notify() {
+	for l := range s.listeners {
+		select {
+		case l <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// humanPlayer receives its moves from the browser. It is an InteractivePlayer, so it is not subject to the
+// timeout, and it can take back moves.
+type humanPlayer struct {
+	server *Server
+	game   *game
+	undo   bool
+}
+
+func (h *humanPlayer) NextMove(state blokus.State, color blokus.Color, timeout sc.Timeout) blokus.Move {
+	s, g := h.server, h.game
+	s.mu.Lock()
+	g.waiting = true
+	g.waitingColor = color
+	blokus.CopyState(&g.waitingState, state)
+	s.notify()
+	s.mu.Unlock()
+
+	var in humanInput
+	select {
+	case in = <-g.input:
+	case <-g.quit:
+	}
+	h.undo = in.undo
+
+	s.mu.Lock()
+	g.waiting = false
+	s.mu.Unlock()
+	return in.move
+}
+
+func (h *humanPlayer) End() {
+}
+
+func (h *humanPlayer) UndoRequested() bool {
+	return h.undo
+}
+
+// SubmitMove passes a move of the human player to the current game. It fails if the game does not wait for a
+// move of the human player, or if the move is not allowed.
+func (s *Server) SubmitMove(m blokus.Move, undo bool) (err error) {
+	s.mu.Lock()
+	g := s.game
+	if g == nil || !g.waiting {
+		s.mu.Unlock()
+		return errors.New("the game is not waiting for a move")
+	}
+	if !undo && !blokus.CanApplyMove(&g.waitingState, g.waitingColor, m) {
+		s.mu.Unlock()
+		return fmt.Errorf("%s cannot play this move", g.waitingColor.String())
+	}
+	s.mu.Unlock()
+	select {
+	case g.input <- humanInput{move: m, undo: undo}:
+	case <-g.quit:
+		err = errors.New("the game has ended")
+	}
+	return
+}
+
+func (s *Server) handlePlayers(w http.ResponseWriter, r *http.Request) {
+	names := []string{HumanPlayerName}
+	for name := range s.Players {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	writeJSON(w, names)
+}
+
+func (s *Server) handleNew(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST expected", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Player1 string `json:"player1"`
+		Player2 string `json:"player2"`
+		Seed    *int64 `json:"seed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	seed := blokus.RandomSeed()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+	if err := s.StartGame(req.Player1, req.Player2, seed); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleGame(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.game == nil {
+		writeJSON(w, nil)
+		return
+	}
+	writeJSON(w, s.game.view())
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, canFlush := w.(http.Flusher)
+	if !canFlush {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	l := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.listeners[l] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, l)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	l <- struct{}{}
+	for {
+		select {
+		case <-l:
+			if _, err := fmt.Fprint(w, "data: update\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleLegal lists the legal placements of a piece of a color in the position after step moves
+func (s *Server) handleLegal(w http.ResponseWriter, r *http.Request) {
+	color, err := blokus.ParseColor(r.FormValue("color"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	piece, err := blokus.ParsePiece(r.FormValue("piece"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	step, err := strconv.Atoi(r.FormValue("step"))
+	if err != nil {
+		http.Error(w, "invalid step: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	var state *blokus.BasicState
+	if g := s.game; g != nil && step >= 0 && step < len(g.states) {
+		state = g.states[step]
+	}
+	s.mu.Unlock()
+	if state == nil {
+		http.Error(w, "no such step", http.StatusNotFound)
+		return
+	}
+	placements := make([]jsonMove, 0)
+	for _, m := range blokus.PossibleNextMoves(state, color) {
+		if m.Transformation.Piece() == piece {
+			placements = append(placements, newJSONMove(color, m))
+		}
+	}
+	writeJSON(w, placements)
+}
+
+func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST expected", http.StatusMethodNotAllowed)
+		return
+	}
+	var req jsonMove
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := req.move()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = s.SubmitMove(m, req.Undo); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,158 @@
+package webui
+
+import (
+	"encoding/json"
+	"github.com/hschendel/sc"
+	"github.com/hschendel/sc/2021/blokus"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// firstMovePlayer always plays the first possible move
+type firstMovePlayer struct{}
+
+func (f firstMovePlayer) NextMove(state blokus.State, color blokus.Color, timeout sc.Timeout) blokus.Move {
+	moves := blokus.PossibleNextMoves(state, color)
+	if len(moves) == 0 {
+		return blokus.EmptyMove
+	}
+	return moves[0]
+}
+
+func (f firstMovePlayer) End() {
+}
+
+// exclusivePlayer plays slowly like firstMovePlayer, and counts the NextMove calls that overlap with another one
+type exclusivePlayer struct {
+	running  int32
+	overlaps int32
+}
+
+func (e *exclusivePlayer) NextMove(state blokus.State, color blokus.Color, timeout sc.Timeout) blokus.Move {
+	if atomic.AddInt32(&e.running, 1) > 1 {
+		atomic.AddInt32(&e.overlaps, 1)
+	}
+	defer atomic.AddInt32(&e.running, -1)
+	time.Sleep(time.Millisecond)
+	return firstMovePlayer{}.NextMove(state, color, timeout)
+}
+
+func (e *exclusivePlayer) End() {
+}
+
+func getGame(t *testing.T, url string) (g jsonGame) {
+	resp, err := http.Get(url + "/api/game")
+	if err != nil {
+		t.Fatalf("cannot get game: %s", err)
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(&g); err != nil {
+		t.Fatalf("cannot decode game: %s", err)
+	}
+	return
+}
+
+// waitForGame polls the game until cond is true
+func waitForGame(t *testing.T, url string, cond func(g jsonGame) bool) (g jsonGame) {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if g = getGame(t, url); cond(g) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timeout waiting for game, last state: %+v", g)
+	return
+}
+
+func post(t *testing.T, url, body string) int {
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("cannot post to %s: %s", url, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestServer(t *testing.T) {
+	srv := httptest.NewServer(NewServer(map[string]blokus.Player{"first": firstMovePlayer{}}, 0))
+	defer srv.Close()
+
+	if code := post(t, srv.URL+"/api/new", `{"player1":"nobody","player2":"first"}`); code != http.StatusBadRequest {
+		t.Errorf("expected status %d for unknown player but got %d", http.StatusBadRequest, code)
+	}
+	if code := post(t, srv.URL+"/api/new", `{"player1":"human","player2":"first","seed":2}`); code != http.StatusNoContent {
+		t.Fatalf("cannot start game, status %d", code)
+	}
+	g := waitForGame(t, srv.URL, func(g jsonGame) bool { return g.Waiting == "BLUE" })
+	if len(g.Positions) != 1 || len(g.Moves) != 0 {
+		t.Fatalf("expected the human to make the first move, but got %d moves", len(g.Moves))
+	}
+
+	resp, err := http.Get(srv.URL + "/api/legal?step=0&color=BLUE&piece=" + g.StartPiece)
+	if err != nil {
+		t.Fatalf("cannot get legal placements: %s", err)
+	}
+	var placements []jsonMove
+	err = json.NewDecoder(resp.Body).Decode(&placements)
+	resp.Body.Close()
+	if err != nil || len(placements) == 0 {
+		t.Fatalf("expected legal placements of %s, got %d, error %v", g.StartPiece, len(placements), err)
+	}
+
+	if code := post(t, srv.URL+"/api/move", `{"skip":true}`); code != http.StatusConflict {
+		t.Errorf("expected status %d for skipping the first move but got %d", http.StatusConflict, code)
+	}
+	move, _ := json.Marshal(placements[0])
+	if code := post(t, srv.URL+"/api/move", string(move)); code != http.StatusNoContent {
+		t.Fatalf("cannot play move, status %d", code)
+	}
+	g = waitForGame(t, srv.URL, func(g jsonGame) bool { return g.Waiting == "RED" })
+	if len(g.Moves) != 2 || g.Moves[0].Piece != placements[0].Piece || g.Moves[1].Color != "YELLOW" {
+		t.Errorf("unexpected moves %+v", g.Moves)
+	}
+
+	if code := post(t, srv.URL+"/api/move", `{"undo":true}`); code != http.StatusNoContent {
+		t.Fatalf("cannot undo, status %d", code)
+	}
+	g = waitForGame(t, srv.URL, func(g jsonGame) bool { return g.Waiting == "BLUE" })
+	if len(g.Moves) != 0 {
+		t.Errorf("expected no moves after undo, but got %d", len(g.Moves))
+	}
+
+	if code := post(t, srv.URL+"/api/new", `{"player1":"first","player2":"first"}`); code != http.StatusNoContent {
+		t.Fatalf("cannot start second game, status %d", code)
+	}
+	g = waitForGame(t, srv.URL, func(g jsonGame) bool { return g.Done && g.ID == 2 })
+	if len(g.Positions) != len(g.Moves)+1 || g.Result == "" {
+		t.Errorf("expected a finished game with one position per move, got %d positions for %d moves", len(g.Positions), len(g.Moves))
+	}
+}
+
+func TestServer_StartGameStopsPreviousGame(t *testing.T) {
+	p := new(exclusivePlayer)
+	s := NewServer(map[string]blokus.Player{"slow": p}, 0)
+	if err := s.StartGame("slow", "slow", 1); err != nil {
+		t.Fatal(err)
+	}
+	first := s.game
+	if err := s.StartGame("slow", "slow", 2); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-first.finished:
+	default:
+		t.Fatalf("expected the first game to be finished when the second one starts")
+	}
+	<-s.game.finished
+	if n := atomic.LoadInt32(&p.overlaps); n != 0 {
+		t.Errorf("expected the games not to ask the player at the same time, but got %d overlapping calls", n)
+	}
+	if len(first.record.Entries) >= len(s.game.record.Entries) {
+		t.Errorf("expected the first game to stop early, but it has %d moves", len(first.record.Entries))
+	}
+}
@@ -0,0 +1,143 @@
+package webui
+
+import (
+	"errors"
+	"github.com/hschendel/sc/2021/blokus"
+)
+
+// jsonGame is the game as sent to the browser, with one position per step of the game
+type jsonGame struct {
+	ID         int            `json:"id"`
+	Player1    string         `json:"player1"`
+	Player2    string         `json:"player2"`
+	StartPiece string         `json:"startPiece"`
+	Moves      []jsonMove     `json:"moves"`
+	Positions  []jsonPosition `json:"positions"`
+	// Waiting contains the color the human player has to move for, if any
+	Waiting string `json:"waiting,omitempty"`
+	Done    bool   `json:"done"`
+	Result  string `json:"result,omitempty"`
+	Score1  uint   `json:"score1"`
+	Score2  uint   `json:"score2"`
+	Error1  string `json:"error1,omitempty"`
+	Error2  string `json:"error2,omitempty"`
+}
+
+type jsonPosition struct {
+	// Board contains 20 rows of 20 characters, each being the letter of a color, or '.' for an empty cell
+	Board     string     `json:"board"`
+	Remaining [][]string `json:"remaining"`
+}
+
+type jsonMove struct {
+	Color      string           `json:"color,omitempty"`
+	Skip       bool             `json:"skip,omitempty"`
+	Undo       bool             `json:"undo,omitempty"`
+	Piece      string           `json:"piece,omitempty"`
+	Rotation   string           `json:"rotation,omitempty"`
+	Flipped    bool             `json:"flipped,omitempty"`
+	X          uint8            `json:"x"`
+	Y          uint8            `json:"y"`
+	Cells      [][2]uint8       `json:"cells,omitempty"`
+	ThinkTime  string           `json:"thinkTime,omitempty"`
+	Evaluation []jsonEvaluation `json:"evaluation,omitempty"`
+}
+
+type jsonEvaluation struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+func newJSONMove(c blokus.Color, m blokus.Move) (jm jsonMove) {
+	jm.Color = c.String()
+	if m.IsEmpty() {
+		jm.Skip = true
+		return
+	}
+	jm.Piece = m.Transformation.Piece().String()
+	jm.Rotation = m.Transformation.Rotation().String()
+	jm.Flipped = m.Transformation.Flipped()
+	jm.X, jm.Y = m.X, m.Y
+	for _, pos := range m.Transformation.Positions() {
+		jm.Cells = append(jm.Cells, [2]uint8{m.X + pos.X, m.Y + pos.Y})
+	}
+	return
+}
+
+// move returns the move sent by the browser
+func (jm *jsonMove) move() (m blokus.Move, err error) {
+	if jm.Skip || jm.Undo {
+		return
+	}
+	if jm.Piece == "" {
+		err = errors.New("piece missing")
+		return
+	}
+	var p blokus.Piece
+	var r blokus.Rotation
+	if p, err = blokus.ParsePiece(jm.Piece); err != nil {
+		return
+	}
+	if r, err = blokus.ParseRotation(jm.Rotation); err != nil {
+		return
+	}
+	m = blokus.NewMove(blokus.NewTransformedPiece(p, r, jm.Flipped), jm.X, jm.Y)
+	return
+}
+
+func newJSONPosition(s blokus.State) (jp jsonPosition) {
+	board := make([]byte, 0, 20*20)
+	for y := uint8(0); y < 20; y++ {
+		for x := uint8(0); x < 20; x++ {
+			if c, hasPiece := s.At(x, y); hasPiece {
+				board = append(board, c.Letter())
+			} else {
+				board = append(board, '.')
+			}
+		}
+	}
+	jp.Board = string(board)
+	jp.Remaining = make([][]string, 4)
+	for c := blokus.Color(0); c < 4; c++ {
+		jp.Remaining[c] = make([]string, 0, len(blokus.AllPieces))
+		for _, p := range s.NotPlayedPiecesFor(c) {
+			jp.Remaining[c] = append(jp.Remaining[c], p.String())
+		}
+	}
+	return
+}
+
+// view returns g for sending it to the browser. Server.mu must be locked.
+func (g *game) view() (jg jsonGame) {
+	jg = jsonGame{
+		ID:         g.id,
+		Player1:    g.record.Player1Name,
+		Player2:    g.record.Player2Name,
+		StartPiece: g.record.StartPiece.String(),
+		Moves:      make([]jsonMove, 0, len(g.record.Entries)),
+		Positions:  make([]jsonPosition, 0, len(g.states)),
+		Done:       g.done,
+	}
+	for _, e := range g.record.Entries {
+		jm := newJSONMove(e.Color, e.Move)
+		jm.ThinkTime = e.ThinkTime.String()
+		for _, ev := range e.Evaluation {
+			jm.Evaluation = append(jm.Evaluation, jsonEvaluation{Name: ev.Name, Value: ev.Value})
+		}
+		jg.Moves = append(jg.Moves, jm)
+	}
+	for _, s := range g.states {
+		jg.Positions = append(jg.Positions, newJSONPosition(s))
+	}
+	if g.waiting {
+		jg.Waiting = g.waitingColor.String()
+	}
+	if g.done {
+		jg.Result = g.record.Result.String()
+		jg.Score1 = g.record.Score1
+		jg.Score2 = g.record.Score2
+		jg.Error1 = g.record.Error1
+		jg.Error2 = g.record.Error2
+	}
+	return
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/hschendel/sc/2021/blokus"
+	"github.com/hschendel/sc/2021/blokus/dataset"
+	"github.com/hschendel/sc/2021/blokus/example_players"
+	"github.com/hschendel/sc/2021/blokus/tune"
+	"os"
+)
+
+var playerNames = []string{"quick", "random", "restrict", "weighted"}
+
+// newPlayer returns a new player, so that both players of a game have their own state even if they are the same bot
+func newPlayer(name string) blokus.Player {
+	switch name {
+	case "quick":
+		return new(example_players.QuickPlayer)
+	case "random":
+		return new(example_players.RandomPlayer)
+	case "restrict":
+		return new(example_players.RestrictingPlayer)
+	case "weighted":
+		return &tune.WeightedPlayer{WeightsFile: "weights.json"}
+	default:
+		return nil
+	}
+}
+
+// blokus_dataset plays games between two players and writes a training record for every move, see package dataset
+// for the file format.
+func main() {
+	out := flag.String("o", "dataset.bin", "write the records to this file")
+	games := flag.Uint("n", 10, "number of games")
+	seed := flag.Int64("seed", 0, "seed for the start pieces, random if 0")
+	augment := flag.Bool("augment", true, "add the records of all symmetries of every position")
+	dedup := flag.Bool("dedup", true, "skip positions and moves that were written before")
+	timeout := flag.Duration("timeout", blokus.DefaultMoveTimeout, "time a player has for a move")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <player 1> <player 2>\n\nAvailable players:\n", os.Args[0])
+		for _, name := range playerNames {
+			fmt.Fprintf(os.Stderr, "  - %s\n", name)
+		}
+		fmt.Fprintln(os.Stderr, "\nFlags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	player1, player2 := newPlayer(flag.Arg(0)), newPlayer(flag.Arg(1))
+	if player1 == nil || player2 == nil {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot create dataset: %s\n", err)
+		os.Exit(2)
+	}
+	bw := bufio.NewWriter(f)
+	w, err := dataset.NewWriter(bw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot write dataset: %s\n", err)
+		os.Exit(2)
+	}
+	g := dataset.Generator{
+		Player1:     player1,
+		Player2:     player2,
+		Player1Name: flag.Arg(0),
+		Player2Name: flag.Arg(1),
+		Games:       *games,
+		Seed:        *seed,
+		MoveTimeout: *timeout,
+		Augment:     *augment,
+		Dedup:       *dedup,
+		LogTo:       os.Stdout,
+	}
+	stats, err := g.Run(w)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot write dataset: %s\n", err)
+		os.Exit(2)
+	}
+	fmt.Printf("%d games, %d positions, %d records written, %d duplicates skipped\n", stats.Games, stats.Positions, stats.Records, stats.Duplicates)
+}
@@ -6,6 +6,6 @@ import (
 )
 
 func main() {
-	player := new(example_players.QuickPlayer)
+	player := &example_players.QuickPlayer{LogBoard: true}
 	blokus.ClientMain(player)
 }
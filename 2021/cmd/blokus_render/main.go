@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/hschendel/sc/2021/blokus"
+	"github.com/hschendel/sc/2021/blokus/render"
+	"os"
+	"path/filepath"
+)
+
+// blokus_render draws a game record (as written by blokus.SaveGameRecord) either as an animated GIF or as
+// one PNG or SVG image per position.
+func main() {
+	format := flag.String("format", "gif", "output format: gif, png or svg")
+	out := flag.String("o", "", "output file for gif, output directory for png and svg frames")
+	cellSize := flag.Int("cell", render.DefaultCellSize, "cell size in pixels")
+	delay := flag.Int("delay", 50, "gif frame delay in 100ths of a second")
+	remaining := flag.Bool("remaining", true, "show remaining pieces per color")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <game record file>\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *out == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+	record, err := blokus.LoadGameRecord(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot load game record: %s\n", err)
+		os.Exit(1)
+	}
+	states, err := blokus.Replay(record)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot replay game record: %s\n", err)
+		os.Exit(1)
+	}
+	lastMoves := make([]blokus.Move, len(states))
+	for i, e := range record.Entries {
+		lastMoves[i+1] = e.Move
+	}
+	opts := render.Options{CellSize: *cellSize, ShowRemaining: *remaining}
+
+	switch *format {
+	case "gif":
+		err = writeGIF(*out, states, lastMoves, opts, *delay)
+	case "png", "svg":
+		err = writeFrames(*out, *format, states, lastMoves, opts)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+func writeGIF(path string, states []*blokus.BasicState, lastMoves []blokus.Move, opts render.Options, delay int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	frames := make([]blokus.State, len(states))
+	for i, s := range states {
+		frames[i] = s
+	}
+	if err = render.GIF(f, frames, lastMoves, opts, delay); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeFrames(dir, format string, states []*blokus.BasicState, lastMoves []blokus.Move, opts render.Options) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for i, s := range states {
+		f, err := os.Create(filepath.Join(dir, fmt.Sprintf("frame-%03d.%s", i, format)))
+		if err != nil {
+			return err
+		}
+		opts.LastMove = lastMoves[i]
+		if format == "png" {
+			err = render.PNG(f, s, opts)
+		} else {
+			err = render.SVG(f, s, opts)
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+		if err = f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
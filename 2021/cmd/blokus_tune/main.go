@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/hschendel/sc/2021/blokus"
+	"github.com/hschendel/sc/2021/blokus/tune"
+	"os"
+)
+
+// blokus_tune tunes the weights of tune.WeightedPlayer with SPSA self-play, and writes the best weights found so far
+// to a file, see tune.SPSA.Run. The weighted player of botmatch loads them from weights.json.
+func main() {
+	out := flag.String("o", "weights.json", "write the tuned weights to this file")
+	start := flag.String("start", "", "start with the weights from this file instead of the default weights")
+	iterations := flag.Uint("n", tune.DefaultIterations, "number of iterations")
+	games := flag.Uint("games", tune.DefaultGamesPerIteration, "games per iteration")
+	stepSize := flag.Float64("step", tune.DefaultStepSize, "step size for changing the weights")
+	perturbation := flag.Float64("perturbation", tune.DefaultPerturbation, "amount the weights are changed for playing")
+	seed := flag.Int64("seed", 1, "seed for all random decisions, for reproducible runs")
+	timeout := flag.Duration("timeout", blokus.DefaultMoveTimeout, "time a player has for a move")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	t := tune.SPSA{
+		Iterations:        *iterations,
+		GamesPerIteration: *games,
+		StepSize:          *stepSize,
+		Perturbation:      *perturbation,
+		Seed:              *seed,
+		MoveTimeout:       *timeout,
+		LogTo:             os.Stdout,
+		OnIteration: func(it *tune.Iteration) {
+			if it.Best {
+				saveWeights(*out, it.Weights)
+			}
+		},
+	}
+	if *start != "" {
+		var err error
+		if t.Start, err = tune.LoadWeights(*start); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot load weights: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	saveWeights(*out, t.Run())
+}
+
+func saveWeights(fileName string, w tune.Weights) {
+	if err := tune.SaveWeights(fileName, w); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot save weights: %s\n", err)
+		os.Exit(2)
+	}
+}
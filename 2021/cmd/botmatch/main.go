@@ -2,13 +2,17 @@ package main
 
 import (
 	"github.com/hschendel/sc/2021/blokus"
+	"github.com/hschendel/sc/2021/blokus/endgame"
 	"github.com/hschendel/sc/2021/blokus/example_players"
+	"github.com/hschendel/sc/2021/blokus/tune"
 )
 
 var players = map[string]blokus.Player{
-	"quick":    new(example_players.QuickPlayer),
-	"random":   new(example_players.RandomPlayer),
-	"restrict": new(example_players.RestrictingPlayer),
+	"quick":         new(example_players.QuickPlayer),
+	"quick-endgame": &endgame.Player{Player: new(example_players.QuickPlayer)},
+	"random":        new(example_players.RandomPlayer),
+	"restrict":      new(example_players.RestrictingPlayer),
+	"weighted":      &tune.WeightedPlayer{WeightsFile: "weights.json"},
 }
 
 func main() {
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"github.com/hschendel/sc/2021/blokus"
+	"os"
+)
+
+// record2replay converts a game record (as written by blokus.SaveGameRecord) into a replay file for the
+// official GUI. If the replay file name ends with .gz, it is compressed.
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintf(os.Stderr, "Usage: %s <game record file> <replay file>\n", os.Args[0])
+		os.Exit(1)
+	}
+	record, err := blokus.LoadGameRecord(os.Args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot load game record: %s\n", err)
+		os.Exit(1)
+	}
+	if err = blokus.SaveReplay(os.Args[2], record); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot save replay: %s\n", err)
+		os.Exit(2)
+	}
+}